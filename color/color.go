@@ -2,7 +2,9 @@ package color
 
 import (
 	"fmt"
-	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
 )
 
 func Text(code int, value interface{}) string {
@@ -13,7 +15,68 @@ func Bg(code int, value interface{}) string {
 	return fmt.Sprintf("\u001b[48;5;%dm%s\u001b[0m", code, value)
 }
 
+// RemoveAsciiColors is kept for backwards compatibility, use Strip instead.
 func RemoveAsciiColors(s string) string {
-	re := regexp.MustCompile("\x1b\\[([0-9]{1,2}(;[0-9]{1,2})?)?[m|K]")
-	return re.ReplaceAllString(s, "")
+	return Strip(s)
+}
+
+// Strip removes all ANSI escape sequences from s, including SGR colors,
+// other CSI sequences like cursor movements and OSC sequences like
+// hyperlinks.
+func Strip(s string) string {
+	if !strings.ContainsRune(s, '\u001b') {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); {
+		if s[i] != '\u001b' {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		i = skipSequence(s, i)
+	}
+
+	return b.String()
+}
+
+// Width returns the amount of cells s occupies in a terminal.
+func Width(s string) int {
+	return runewidth.StringWidth(Strip(s))
+}
+
+// skipSequence returns the position after the escape sequence starting at i.
+func skipSequence(s string, i int) int {
+	i++
+	if i >= len(s) {
+		return i
+	}
+
+	switch s[i] {
+	case '[':
+		// CSI: parameter and intermediate bytes followed by a final byte
+		for i++; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return i
+	case ']', 'P', '_', '^':
+		// OSC and other strings are terminated by BEL or ST (ESC \)
+		for i++; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\u001b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return i
+	}
+
+	return i + 1
 }
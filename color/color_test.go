@@ -0,0 +1,81 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStyle(t *testing.T) {
+	brand := NewStyle().Foreground(RGB(255, 0, 135)).Bold()
+
+	tests := []struct {
+		name    string
+		style   Style
+		profile Profile
+		want    string
+	}{
+		{"No color", brand, NoColor, "value"},
+		{"Truecolor", brand, TrueColor, "\u001b[1;38;2;255;0;135mvalue\u001b[0m"},
+		{"Truecolor downgraded to 256 colors", brand, ANSI256, "\u001b[1;38;5;198mvalue\u001b[0m"},
+		{"Truecolor downgraded to 16 colors", brand, ANSI, "\u001b[1;35mvalue\u001b[0m"},
+		{"Named colors follow the terminal theme", NewStyle().Foreground(mustNamed("bright-cyan")).Background(mustNamed("blue")), TrueColor, "\u001b[96;44mvalue\u001b[0m"},
+		{"Attributes", NewStyle().Italic().Underline().Dim().Strikethrough(), ANSI, "\u001b[2;3;4;9mvalue\u001b[0m"},
+		{"Inherit", NewStyle().Underline().Inherit(brand), ANSI256, "\u001b[1;4;38;5;198mvalue\u001b[0m"},
+		{"Hyperlink", NewStyle().Link("https://evoli.dev"), ANSI, "\u001b]8;;https://evoli.dev\u001b\\value\u001b]8;;\u001b\\"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.style.Render(test.profile, "value"))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	c, err := Parse("#f0a")
+	assert.Nil(t, err)
+	assert.Equal(t, RGB(255, 0, 170), c)
+
+	c, err = Parse("169")
+	assert.Nil(t, err)
+	assert.Equal(t, Code(169), c)
+
+	_, err = Parse("#12345")
+	assert.NotNil(t, err)
+
+	_, err = Parse("purple-ish")
+	assert.NotNil(t, err)
+}
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{Text(169, "palette"), "palette"},
+		{Bg(210, "background"), "background"},
+		{NewStyle().Foreground(RGB(1, 2, 3)).Bold().Render(TrueColor, "truecolor"), "truecolor"},
+		{Hyperlink("https://evoli.dev", "link"), "link"},
+		{"\u001b]0;title\u0007text", "text"},
+		{"\r\u001b[2A\u001b[Jcursor", "\rcursor"},
+		{"plain", "plain"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Strip(test.value))
+	}
+
+	assert.Equal(t, "palette", RemoveAsciiColors(Text(169, "palette")))
+}
+
+func TestWidth(t *testing.T) {
+	assert.Equal(t, 5, Width(NewStyle().Bold().Render(ANSI256, "hello")))
+	assert.Equal(t, 4, Width(Text(169, "日本")))
+	assert.Equal(t, 4, Width(Hyperlink("https://evoli.dev", "link")))
+}
+
+func mustNamed(name string) Color {
+	c, _ := Named(name)
+	return c
+}
@@ -0,0 +1,97 @@
+package color
+
+import "fmt"
+
+// Profile describes how many colors a terminal is able to display. Colors
+// are always defined as 256-color codes and converted to the profile when
+// they are rendered.
+type Profile int
+
+const (
+	NoColor Profile = iota
+	ANSI
+	ANSI256
+	TrueColor
+)
+
+func (p Profile) String() string {
+	switch p {
+	case ANSI:
+		return "ansi"
+	case ANSI256:
+		return "ansi256"
+	case TrueColor:
+		return "truecolor"
+	}
+
+	return "none"
+}
+
+func (p Profile) Text(code int, value interface{}) string {
+	return NewStyle().Foreground(Code(code)).Render(p, value)
+}
+
+func (p Profile) Bg(code int, value interface{}) string {
+	return NewStyle().Background(Code(code)).Render(p, value)
+}
+
+func rgbSequence(r, g, b uint8, background bool) string {
+	if background {
+		return fmt.Sprintf("48;2;%d;%d;%d", r, g, b)
+	}
+
+	return fmt.Sprintf("38;2;%d;%d;%d", r, g, b)
+}
+
+// ansiPalette holds the xterm default values of the 16 basic colors.
+var ansiPalette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+func ansi256ToRGB(code int) (uint8, uint8, uint8) {
+	switch {
+	case code < 0:
+		return 0, 0, 0
+	case code < 16:
+		c := ansiPalette[code]
+		return c[0], c[1], c[2]
+	case code < 232:
+		code -= 16
+		return cubeLevels[code/36], cubeLevels[(code/6)%6], cubeLevels[code%6]
+	case code < 256:
+		level := uint8(8 + (code-232)*10)
+		return level, level, level
+	}
+
+	return 255, 255, 255
+}
+
+func ansi256ToANSI(code int) int {
+	if code >= 0 && code < 16 {
+		return code
+	}
+
+	r, g, b := ansi256ToRGB(code)
+	return rgbToANSI(r, g, b)
+}
+
+func rgbToANSI(r, g, b uint8) int {
+	best, bestDistance := 0, -1
+	for i, c := range ansiPalette {
+		dr := int(r) - int(c[0])
+		dg := int(g) - int(c[1])
+		db := int(b) - int(c[2])
+
+		distance := dr*dr + dg*dg + db*db
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+
+	return best
+}
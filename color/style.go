@@ -0,0 +1,335 @@
+package color
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type colorKind int
+
+const (
+	noColor colorKind = iota
+	basicColor
+	paletteColor
+	rgbColor
+)
+
+// Color is a terminal color. It is either one of the 16 basic colors which
+// follow the theme of the terminal, a 256-color code or a truecolor value.
+type Color struct {
+	kind    colorKind
+	code    int
+	r, g, b uint8
+}
+
+var names = map[string]int{
+	"black":   0,
+	"red":     1,
+	"green":   2,
+	"yellow":  3,
+	"blue":    4,
+	"magenta": 5,
+	"cyan":    6,
+	"white":   7,
+	"gray":    8,
+	"grey":    8,
+}
+
+func Code(code int) Color {
+	return Color{kind: paletteColor, code: code}
+}
+
+func RGB(r, g, b uint8) Color {
+	return Color{kind: rgbColor, r: r, g: g, b: b}
+}
+
+// Named returns one of the basic colors, e.g. "red" or "bright-red".
+func Named(name string) (Color, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	bright := strings.HasPrefix(name, "bright-")
+	code, ok := names[strings.TrimPrefix(name, "bright-")]
+	if !ok {
+		return Color{}, false
+	}
+
+	if bright && code < 8 {
+		code += 8
+	}
+
+	return Color{kind: basicColor, code: code}, true
+}
+
+// Hex parses colors in the #rgb and #rrggbb notation.
+func Hex(hex string) (Color, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+
+	if len(value) != 6 {
+		return Color{}, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	rgb, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	return RGB(uint8(rgb>>16), uint8(rgb>>8), uint8(rgb)), nil
+}
+
+// Parse accepts a color name, a hex value or a 256-color code.
+func Parse(value string) (Color, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(value, "#") {
+		return Hex(value)
+	}
+
+	if c, ok := Named(value); ok {
+		return c, nil
+	}
+
+	if code, err := strconv.Atoi(value); err == nil && code >= 0 && code < 256 {
+		return Code(code), nil
+	}
+
+	return Color{}, fmt.Errorf("unknown color %q", value)
+}
+
+func (c Color) IsZero() bool {
+	return c.kind == noColor
+}
+
+func (c Color) sequence(p Profile, background bool) string {
+	switch c.kind {
+	case basicColor:
+		return basicSequence(c.code, background)
+	case paletteColor:
+		switch p {
+		case ANSI:
+			return basicSequence(ansi256ToANSI(c.code), background)
+		case TrueColor:
+			r, g, b := ansi256ToRGB(c.code)
+			return rgbSequence(r, g, b, background)
+		}
+
+		return paletteSequence(c.code, background)
+	case rgbColor:
+		switch p {
+		case ANSI:
+			return basicSequence(rgbToANSI(c.r, c.g, c.b), background)
+		case ANSI256:
+			return paletteSequence(rgbToANSI256(c.r, c.g, c.b), background)
+		}
+
+		return rgbSequence(c.r, c.g, c.b, background)
+	}
+
+	return ""
+}
+
+type attribute int
+
+const (
+	bold attribute = 1 << iota
+	dim
+	italic
+	underline
+	strikethrough
+)
+
+var attributeCodes = []struct {
+	attribute attribute
+	code      string
+}{
+	{bold, "1"},
+	{dim, "2"},
+	{italic, "3"},
+	{underline, "4"},
+	{strikethrough, "9"},
+}
+
+// Style combines colors, text attributes and a hyperlink. Styles are values,
+// every method returns a modified copy so a palette can be defined once and
+// extended where it is used.
+type Style struct {
+	fg         Color
+	bg         Color
+	attributes attribute
+	link       string
+}
+
+func NewStyle() Style {
+	return Style{}
+}
+
+func (s Style) Foreground(c Color) Style {
+	s.fg = c
+	return s
+}
+
+func (s Style) Background(c Color) Style {
+	s.bg = c
+	return s
+}
+
+func (s Style) Bold() Style {
+	s.attributes |= bold
+	return s
+}
+
+func (s Style) Dim() Style {
+	s.attributes |= dim
+	return s
+}
+
+func (s Style) Italic() Style {
+	s.attributes |= italic
+	return s
+}
+
+func (s Style) Underline() Style {
+	s.attributes |= underline
+	return s
+}
+
+func (s Style) Strikethrough() Style {
+	s.attributes |= strikethrough
+	return s
+}
+
+// Link turns the styled text into a hyperlink (OSC 8).
+func (s Style) Link(url string) Style {
+	s.link = url
+	return s
+}
+
+// Inherit returns a style with the colors and link of s, falling back to
+// those of parent, and the attributes of both.
+func (s Style) Inherit(parent Style) Style {
+	if s.fg.IsZero() {
+		s.fg = parent.fg
+	}
+	if s.bg.IsZero() {
+		s.bg = parent.bg
+	}
+	if s.link == "" {
+		s.link = parent.link
+	}
+	s.attributes |= parent.attributes
+
+	return s
+}
+
+func (s Style) IsZero() bool {
+	return s == Style{}
+}
+
+// Sequence returns the SGR sequence which enables the style.
+func (s Style) Sequence(p Profile) string {
+	if p == NoColor {
+		return ""
+	}
+
+	var params []string
+	for _, a := range attributeCodes {
+		if s.attributes&a.attribute != 0 {
+			params = append(params, a.code)
+		}
+	}
+	if !s.fg.IsZero() {
+		params = append(params, s.fg.sequence(p, false))
+	}
+	if !s.bg.IsZero() {
+		params = append(params, s.bg.sequence(p, true))
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+
+	return "\u001b[" + strings.Join(params, ";") + "m"
+}
+
+func (s Style) Render(p Profile, value interface{}) string {
+	text := fmt.Sprintf("%v", value)
+	if p == NoColor {
+		return text
+	}
+
+	if sequence := s.Sequence(p); sequence != "" {
+		text = sequence + text + Reset
+	}
+
+	if s.link != "" {
+		text = Hyperlink(s.link, text)
+	}
+
+	return text
+}
+
+// Reset disables all colors and attributes.
+const Reset = "\u001b[0m"
+
+func Hyperlink(url string, text string) string {
+	return "\u001b]8;;" + url + "\u001b\\" + text + "\u001b]8;;\u001b\\"
+}
+
+func basicSequence(code int, background bool) string {
+	base := 30
+	if background {
+		base = 40
+	}
+
+	if code > 7 {
+		return strconv.Itoa(base + 60 + code - 8)
+	}
+
+	return strconv.Itoa(base + code)
+}
+
+func paletteSequence(code int, background bool) string {
+	if background {
+		return fmt.Sprintf("48;5;%d", code)
+	}
+
+	return fmt.Sprintf("38;5;%d", code)
+}
+
+func rgbToANSI256(r, g, b uint8) int {
+	// grays map better onto the gray ramp than onto the color cube
+	if r == g && g == b {
+		if r < 8 {
+			return 16
+		}
+		if r > 238 {
+			return 231
+		}
+
+		return 232 + (int(r)-8)/10
+	}
+
+	return 16 + 36*cubeIndex(r) + 6*cubeIndex(g) + cubeIndex(b)
+}
+
+func cubeIndex(v uint8) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if absDiff(v, level) < absDiff(v, cubeLevels[best]) {
+			best = i
+		}
+	}
+
+	return best
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+
+	return int(b - a)
+}
@@ -1,14 +1,23 @@
 package console
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/evolidev/console/color"
+	"github.com/evolidev/console/container"
+	"github.com/evolidev/console/lock"
+	"github.com/evolidev/console/markup"
 	"github.com/evolidev/console/parse"
+	"github.com/evolidev/console/schedule"
+	"github.com/evolidev/console/terminal"
 	"github.com/olekukonko/tablewriter"
 )
 
@@ -16,11 +25,45 @@ type Command struct {
 	Definition  string
 	Description string
 	Execution   func(c *parse.ParsedCommand)
+	Handler     func(ctx *Context) error
+	Factory     func() (func(ctx *Context) error, error)
+	// Isolated commands hold a file lock while they run, so they can't run
+	// twice at the same time, not even from different processes.
+	Isolated    bool
+	LockPath    string
+	LockTimeout time.Duration
+	definition  *parse.Definition
+}
+
+// Spec returns the compiled definition of the command. It is compiled once
+// when the command is registered.
+func (cmd *Command) Spec() *parse.Definition {
+	if cmd.definition == nil || cmd.definition.Source != cmd.Definition {
+		cmd.definition = parse.MustCompile(cmd.Definition)
+	}
+
+	return cmd.definition
+}
+
+// lockPath returns the lock file of an isolated command, by default one per
+// executable and command in the temporary directory.
+func (cmd *Command) lockPath() string {
+	if cmd.LockPath != "" {
+		return cmd.LockPath
+	}
+
+	app := "console"
+	if executable, err := os.Executable(); err == nil {
+		app = filepath.Base(executable)
+	}
+
+	name := strings.ReplaceAll(cmd.GetName(), ":", "-")
+
+	return filepath.Join(os.TempDir(), app+"-"+name+".lock")
 }
 
 func (cmd *Command) GetName() string {
-	parts := strings.Split(cmd.Definition, " ")
-	return parts[0]
+	return cmd.Spec().Name
 }
 
 func (cmd *Command) GetCommand() string {
@@ -46,60 +89,184 @@ type CommandGroup struct {
 }
 
 type Console struct {
-	Commands map[string]*Command
-	Coloring bool
-	Output   io.Writer
-	Title    string
+	Commands  map[string]*Command
+	Coloring  bool
+	Profile   color.Profile
+	Output    io.Writer
+	ErrOutput io.Writer
+	Input     io.Reader
+	Env       map[string]string
+	Title     string
+	Quiet     bool
+	Verbosity Verbosity
+	LogFormat LogFormat
+	Formatter *markup.Formatter
+	Container *container.Container
+	progress  *progressArea
+
+	// colorsChosen is set once colors were enabled or disabled explicitly,
+	// SetOutput keeps that choice instead of detecting the new output.
+	colorsChosen bool
+
+	schedule          *schedule.Schedule
+	booted            bool
+	bootErr           error
+	origins           map[string]string
+	plugins           []pluginCommand
+	disabledProviders map[string]bool
 }
 
 func (c *Console) Run() {
 	args := os.Args[1:]
 
-	c.Call(args)
+	err := c.Execute(context.Background(), args)
+	if err != nil {
+		c.printError(err)
+		os.Exit(ExitCode(err))
+	}
 }
 
 func (c *Console) Call(args []string) {
+	err := c.Execute(context.Background(), args)
+	if err != nil {
+		c.printError(err)
+	}
+}
+
+// Execute runs the command for the given arguments and returns its error.
+// When no command is given the available commands are rendered.
+func (c *Console) Execute(ctx context.Context, args []string) error {
+	quiet, verbosity := c.Quiet, c.Verbosity
+	defer func() {
+		c.Quiet, c.Verbosity = quiet, verbosity
+	}()
+
+	if err := c.Boot(); err != nil {
+		return err
+	}
 
-	args = cleanArgs(args)
+	raw := args
+	args = c.parseVerbosity(cleanArgs(args))
 	if len(args) > 0 {
 		command := args[0]
-		arguments := strings.Join(args, " ")
 
 		if cmd, ok := c.Commands[command]; ok {
-			parsed := parse.Parse(cmd.Definition, arguments)
-			cmd.Execution(parsed)
-			return
-		} else {
-			c.Println()
-			c.Println(c.Bg(210, fmt.Sprintf("%46s", " ")))
-			c.Println(
-				c.Bg(210, c.Text(255, fmt.Sprintf("%s", "    Sorry, but the command does not exist:    "))),
-			)
-			c.Println(c.Bg(210, fmt.Sprintf("%46s", " ")))
-			c.Println()
+			return c.execute(ctx, cmd, cmd.Spec().Parse(args), args, argsAfter(raw, command))
 		}
+
+		c.Println()
+		c.Println(c.Bg(210, fmt.Sprintf("%46s", " ")))
+		c.Println(
+			c.Bg(210, c.Text(255, fmt.Sprintf("%s", "    Sorry, but the command does not exist:    "))),
+		)
+		c.Println(c.Bg(210, fmt.Sprintf("%46s", " ")))
+		c.Println()
+		c.Render()
+
+		return fmt.Errorf("%w: %s", ErrCommandNotFound, command)
 	}
 
 	c.Render()
+
+	return nil
 }
 
+func (c *Console) execute(ctx context.Context, cmd *Command, parsed *parse.ParsedCommand, args []string, raw []string) error {
+	if cmd.Isolated {
+		l := lock.New(cmd.lockPath())
+		l.Timeout = cmd.LockTimeout
+
+		if err := l.Acquire(ctx); err != nil {
+			return fmt.Errorf("%s is already running: %w", cmd.GetName(), err)
+		}
+		defer l.Release()
+	}
+
+	handler := cmd.Handler
+	if handler == nil && cmd.Factory != nil {
+		var err error
+		if handler, err = cmd.Factory(); err != nil {
+			return fmt.Errorf("build %s: %w", cmd.GetName(), err)
+		}
+	}
+
+	if handler != nil {
+		return handler(&Context{
+			Context:       ctx,
+			ParsedCommand: parsed,
+			Console:       c,
+			Args:          args,
+			rawArgs:       raw,
+		})
+	}
+
+	if cmd.Execution != nil {
+		cmd.Execution(parsed)
+	}
+
+	return nil
+}
+
+func (c *Console) printError(err error) {
+	if errors.Is(err, ErrCommandNotFound) {
+		return
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Err == nil {
+		return
+	}
+
+	c.PrintErrln(c.Text(203, "Error: ") + markup.Escape(err.Error()))
+}
+
+// Add registers a command. It panics when the definition of the command is
+// invalid, so broken definitions are noticed at startup.
 func (c *Console) Add(command *Command) {
+	command.Spec()
+
 	c.Commands[command.GetName()] = command
+	delete(c.origins, command.GetName())
 }
 
 func (c *Console) AddCommand(name string, description string, execution func(c *parse.ParsedCommand)) *Command {
-	command := &Command{name, description, execution}
+	command := &Command{Definition: name, Description: description, Execution: execution}
+	c.Add(command)
+
+	return command
+}
+
+// AddHandler registers a command whose handler can fail.
+func (c *Console) AddHandler(name string, description string, handler func(ctx *Context) error) *Command {
+	command := &Command{Definition: name, Description: description, Handler: handler}
+	c.Add(command)
+
+	return command
+}
+
+// AddLazy registers a command whose handler is built by factory each time
+// the command runs. Rendering and completing the commands never calls the
+// factory, so expensive dependencies are only constructed when needed.
+func (c *Console) AddLazy(name string, description string, factory func() (func(ctx *Context) error, error)) *Command {
+	command := &Command{Definition: name, Description: description, Factory: factory}
 	c.Add(command)
 
 	return command
 }
 
 func New() *Console {
-	return &Console{
-		Commands: make(map[string]*Command),
-		Coloring: true,
-		Output:   os.Stdout,
+	c := &Console{
+		Commands:  make(map[string]*Command),
+		Output:    os.Stdout,
+		ErrOutput: os.Stderr,
+		Input:     os.Stdin,
+		Formatter: markup.New(),
+		Container: container.New(),
 	}
+	c.progress = &progressArea{console: c}
+	c.DetectTerminal()
+
+	return c
 }
 
 func groupCommands(commands map[string]*Command) []CommandGroup {
@@ -142,6 +309,12 @@ func groupCommands(commands map[string]*Command) []CommandGroup {
 }
 
 func (c *Console) Render() {
+	if err := c.Boot(); err != nil {
+		c.printError(err)
+	}
+
+	c.describePlugins()
+
 	table := c.SetupTable()
 
 	c.AddCommandsToTable(table)
@@ -184,8 +357,14 @@ func (c *Console) AddCommandsToTable(table *tablewriter.Table) {
 }
 
 func (c *Console) SetupTable() *tablewriter.Table {
+	return c.newTable("AVAILABLE COMMANDS", "")
+}
+
+// newTable returns a table in the style of the list of commands, the first
+// column being highlighted.
+func (c *Console) newTable(headers ...string) *tablewriter.Table {
 	table := tablewriter.NewWriter(c.Output)
-	table.SetHeader([]string{"AVAILABLE COMMANDS", ""})
+	table.SetHeader(headers)
 	table.SetAutoWrapText(false)
 	table.SetAutoFormatHeaders(true)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
@@ -199,15 +378,15 @@ func (c *Console) SetupTable() *tablewriter.Table {
 	table.SetNoWhiteSpace(true)
 
 	if c.Coloring {
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.FgHiMagentaColor},
-			tablewriter.Colors{tablewriter.FgHiBlackColor},
-		)
+		columns := []tablewriter.Colors{{tablewriter.FgHiMagentaColor}}
+		header := []tablewriter.Colors{{tablewriter.FgHiWhiteColor}}
+		for range headers[1:] {
+			columns = append(columns, tablewriter.Colors{tablewriter.FgHiBlackColor})
+			header = append(header, tablewriter.Colors{tablewriter.FgHiBlackColor})
+		}
 
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiWhiteColor},
-			tablewriter.Colors{tablewriter.FgHiBlackColor},
-		)
+		table.SetColumnColor(columns...)
+		table.SetHeaderColor(header...)
 	}
 
 	return table
@@ -215,19 +394,45 @@ func (c *Console) SetupTable() *tablewriter.Table {
 
 func (c *Console) DisableColors() {
 	c.Coloring = false
+	c.colorsChosen = true
 }
 
 func (c *Console) EnableColors() {
 	c.Coloring = true
+	c.colorsChosen = true
+	if c.Profile == color.NoColor {
+		c.Profile = color.ANSI256
+	}
 }
 
+// DetectTerminal enables or disables colors depending on whether the output
+// is a terminal and which colors it supports.
+func (c *Console) DetectTerminal() {
+	c.Profile = terminal.ColorProfile(c.Output)
+	c.Coloring = c.Profile != color.NoColor
+}
+
+// TerminalSize returns the width and height of the output terminal.
+func (c *Console) TerminalSize() (int, int) {
+	return terminal.Size(c.Output)
+}
+
+func (c *Console) SetQuiet(quiet bool) {
+	c.Quiet = quiet
+}
+
+// SetOutput replaces the output and detects whether it is a terminal and
+// which colors it supports, unless colors were enabled or disabled before.
 func (c *Console) SetOutput(output io.Writer) {
 	c.Output = output
+	if !c.colorsChosen {
+		c.DetectTerminal()
+	}
 }
 
 func (c *Console) Text(code int, value interface{}) string {
 	if c.Coloring {
-		return color.Text(code, value)
+		return c.colorProfile().Text(code, value)
 	}
 
 	return fmt.Sprintf("%v", value)
@@ -235,16 +440,67 @@ func (c *Console) Text(code int, value interface{}) string {
 
 func (c *Console) Bg(code int, value interface{}) string {
 	if c.Coloring {
-		return color.Bg(code, value)
+		return c.colorProfile().Bg(code, value)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+func (c *Console) Styled(style color.Style, value interface{}) string {
+	if c.Coloring {
+		return style.Render(c.colorProfile(), value)
 	}
 
 	return fmt.Sprintf("%v", value)
 }
 
+// AddStyle registers a style which can be used as a tag in the output, e.g.
+// AddStyle("brand", style) enables "<brand>text</brand>".
+func (c *Console) AddStyle(name string, style color.Style) {
+	c.formatter().SetStyle(name, style)
+}
+
+// Format replaces the style tags in text, they are removed when coloring
+// is disabled.
+func (c *Console) Format(text string) string {
+	if !c.Coloring {
+		return c.formatter().Strip(text)
+	}
+
+	return c.formatter().Format(c.colorProfile(), text)
+}
+
+func (c *Console) formatter() *markup.Formatter {
+	if c.Formatter == nil {
+		c.Formatter = markup.New()
+	}
+
+	return c.Formatter
+}
+
+func (c *Console) colorProfile() color.Profile {
+	if c.Profile == color.NoColor {
+		return color.ANSI256
+	}
+
+	return c.Profile
+}
+
 func (c *Console) SetTitle(title string) {
 	c.Title = title
 }
 
+// argsAfter returns the arguments after the first occurrence of command.
+func argsAfter(args []string, command string) []string {
+	for i, arg := range args {
+		if arg == command {
+			return append([]string{}, args[i+1:]...)
+		}
+	}
+
+	return nil
+}
+
 func cleanArgs(args []string) []string {
 	var cleaned []string
 	for _, arg := range args {
@@ -257,9 +513,59 @@ func cleanArgs(args []string) []string {
 	return cleaned
 }
 
+// Print formats the style tags in the arguments, see Format. Escape user
+// input with markup.Escape or use Printf.
+func (c *Console) Print(args ...interface{}) {
+	if c.Quiet {
+		return
+	}
+
+	c.area().print(c.Format(fmt.Sprint(args...)))
+}
+
 func (c *Console) Println(args ...interface{}) {
-	_, err := fmt.Fprintln(c.Output, args...)
+	if c.Quiet {
+		return
+	}
+
+	c.area().print(c.Format(fmt.Sprintln(args...)))
+}
+
+// Printf formats the style tags in format, e.g.
+// Printf("<info>Deployed %s</info>\n", version). Tags in the arguments are
+// escaped, so user input is printed as it is.
+func (c *Console) Printf(format string, args ...interface{}) {
+	if c.Quiet {
+		return
+	}
+
+	c.area().print(c.Format(markup.Sprintf(format, args...)))
+}
+
+// PrintErrln writes to the error output, it is not affected by quiet mode.
+func (c *Console) PrintErrln(args ...interface{}) {
+	output := c.ErrOutput
+	if output == nil {
+		output = os.Stderr
+	}
+
+	_, err := fmt.Fprint(output, c.Format(fmt.Sprintln(args...)))
 	if err != nil {
 		panic(err)
 	}
 }
+
+// Getenv reads an environment variable, values in Env take precedence over
+// the environment of the process.
+func (c *Console) Getenv(key string) string {
+	value, _ := c.LookupEnv(key)
+	return value
+}
+
+func (c *Console) LookupEnv(key string) (string, bool) {
+	if value, ok := c.Env[key]; ok {
+		return value, true
+	}
+
+	return os.LookupEnv(key)
+}
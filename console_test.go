@@ -1,12 +1,16 @@
 package console
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/evolidev/console/color"
+	"github.com/evolidev/console/markup"
 	"github.com/evolidev/console/parse"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -113,6 +117,13 @@ func TestParseSimpleCommand(t *testing.T) {
 		assert.Equal(t, "mail:send", cmd.GetName())
 	})
 
+	t.Run("Get name of a definition spanning lines", func(t *testing.T) {
+		cmd := &Command{Definition: "deploy\n\t{env : The environment}"}
+
+		assert.Equal(t, "deploy", cmd.GetName())
+		assert.Equal(t, "deploy", cmd.GetCommand())
+	})
+
 	t.Run("Get prefix of command", func(t *testing.T) {
 		command := "mail:send"
 		definition := "mail:send {user} {--Q|queue}"
@@ -371,6 +382,129 @@ func TestParseSimpleCommand(t *testing.T) {
 
 }
 
+func TestAddInvalidDefinition(t *testing.T) {
+	cli := New()
+
+	assert.PanicsWithError(t, `invalid definition "mail:send {user" at column 16: missing closing '}'`, func() {
+		cli.AddCommand("mail:send {user", "Send email", func(cmd *parse.ParsedCommand) {})
+	})
+	assert.Empty(t, cli.Commands)
+}
+
+func BenchmarkExecute(b *testing.B) {
+	cli := New()
+	cli.AddHandler("mail:send {user} {files?*} {--Q|queue=default} {--dry-run}", "Send email", func(ctx *Context) error {
+		return nil
+	})
+
+	args := []string{"mail:send", "42", "a.go", "b.go", "--queue=high", "-Q", "--dry-run"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := cli.Execute(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAddLazy(t *testing.T) {
+	built := 0
+	factory := func() (func(ctx *Context) error, error) {
+		built++
+		return func(ctx *Context) error {
+			ctx.Println("sent to " + ctx.GetArgument("user").String())
+			return nil
+		}, nil
+	}
+
+	cli := New()
+	cli.DisableColors()
+	cli.Output = io.Discard
+	cli.AddLazy("mail:send {user}", "Send email", factory)
+	cli.AddLazy("broken", "Fails to build", func() (func(ctx *Context) error, error) {
+		return nil, fmt.Errorf("no database")
+	})
+
+	cli.Render()
+	cli.Complete("mail")
+	assert.Equal(t, 0, built)
+
+	var out strings.Builder
+	cli.Output = &out
+	assert.Nil(t, cli.Execute(context.Background(), []string{"mail:send", "42"}))
+	assert.Equal(t, 1, built)
+	assert.Equal(t, "sent to 42\n", out.String())
+
+	err := cli.Execute(context.Background(), []string{"broken"})
+	assert.EqualError(t, err, "build broken: no database")
+}
+
+// expensiveHandler stands in for a handler with heavy dependencies like
+// database connections or parsed templates.
+func expensiveHandler() (func(ctx *Context) error, error) {
+	buffer := make([]byte, 64*1024)
+	for i := range buffer {
+		buffer[i] = byte(i)
+	}
+
+	return func(ctx *Context) error {
+		_ = buffer
+		return nil
+	}, nil
+}
+
+func benchmarkStartup(b *testing.B, lazy bool) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cli := New()
+		cli.Output = io.Discard
+
+		for n := 0; n < 200; n++ {
+			name := fmt.Sprintf("group%d:command%d {name} {--force}", n%10, n)
+			if lazy {
+				cli.AddLazy(name, "A command", expensiveHandler)
+				continue
+			}
+
+			handler, _ := expensiveHandler()
+			cli.AddHandler(name, "A command", handler)
+		}
+
+		cli.Render()
+	}
+}
+
+func BenchmarkStartupEager(b *testing.B) {
+	benchmarkStartup(b, false)
+}
+
+func BenchmarkStartupLazy(b *testing.B) {
+	benchmarkStartup(b, true)
+}
+
+func TestIsolated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrate.lock")
+
+	cli := New()
+	cli.Output = io.Discard
+
+	var nested error
+	migrate := cli.AddHandler("migrate", "", func(ctx *Context) error {
+		nested = ctx.Execute(ctx, []string{"migrate"})
+		return nil
+	})
+	migrate.Isolated = true
+	migrate.LockPath = path
+
+	assert.Nil(t, cli.Execute(context.Background(), []string{"migrate"}))
+	assert.EqualError(t, nested, fmt.Sprintf("migrate is already running: %s is locked by process %d", path, os.Getpid()))
+
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
 func TestText(t *testing.T) {
 	tests := []struct {
 		code    int
@@ -421,6 +555,84 @@ func TestBg(t *testing.T) {
 	}
 }
 
+func TestProfile(t *testing.T) {
+	tests := []struct {
+		profile color.Profile
+		code    int
+		want    string
+	}{
+		{color.NoColor, 169, "value"},
+		{color.ANSI256, 169, "\u001b[38;5;169mvalue\u001b[0m"},
+		{color.ANSI, 1, "\u001b[31mvalue\u001b[0m"},
+		{color.ANSI, 9, "\u001b[91mvalue\u001b[0m"},
+		{color.ANSI, 196, "\u001b[91mvalue\u001b[0m"},
+		{color.ANSI, 232, "\u001b[30mvalue\u001b[0m"},
+		{color.TrueColor, 169, "\u001b[38;2;215;95;175mvalue\u001b[0m"},
+		{color.TrueColor, 244, "\u001b[38;2;128;128;128mvalue\u001b[0m"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s-%d", test.profile, test.code), func(t *testing.T) {
+			assert.Equal(t, test.want, test.profile.Text(test.code, "value"))
+		})
+	}
+
+	assert.Equal(t, "\u001b[44mvalue\u001b[0m", color.ANSI.Bg(4, "value"))
+}
+
+func TestDetectTerminal(t *testing.T) {
+	cli := New()
+	cli.SetOutput(&strings.Builder{})
+
+	if _, ok := os.LookupEnv("FORCE_COLOR"); !ok {
+		assert.False(t, cli.Coloring)
+		assert.Equal(t, "value", cli.Text(169, "value"))
+	}
+
+	cli.EnableColors()
+	assert.Equal(t, color.Text(169, "value"), cli.Text(169, "value"))
+}
+
+func TestSetOutputDetectsTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	cli := New()
+	cli.Coloring, cli.Profile = true, color.TrueColor
+
+	cli.SetOutput(&strings.Builder{})
+	assert.False(t, cli.Coloring)
+	assert.Equal(t, color.NoColor, cli.Profile)
+
+	cli.EnableColors()
+	cli.SetOutput(&strings.Builder{})
+	assert.True(t, cli.Coloring, "an explicit choice is kept")
+}
+
+func TestFormat(t *testing.T) {
+	cli := New()
+	cli.AddStyle("brand", color.NewStyle().Foreground(color.Code(169)))
+
+	out := &strings.Builder{}
+	cli.SetOutput(out)
+
+	cli.DisableColors()
+	cli.Println("<info>Deployed</info> <brand>v1.2</brand>")
+	cli.Printf("<info>Deployed</info> <brand>%s</brand>\n", "v1.3")
+	assert.Equal(t, "Deployed v1.2\nDeployed v1.3\n", out.String())
+
+	// user input is escaped
+	out.Reset()
+	cli.Printf("<info>%s</info>\n", "<b>bold</b> \\<i>")
+	cli.Println("<info>" + markup.Escape("<b>bold</b>") + "</info>")
+	cli.ErrOutput = out
+	cli.printError(errors.New("unexpected <eof>"))
+	assert.Equal(t, "<b>bold</b> \\<i>\n<b>bold</b>\nError: unexpected <eof>\n", out.String())
+
+	cli.EnableColors()
+	cli.Profile = color.ANSI256
+	assert.Equal(t, "\u001b[38;5;169mv1.2\u001b[0m", cli.Format("<brand>v1.2</>"))
+}
+
 func TestGetOptionWithDefault(t *testing.T) {
 	tests := []struct {
 		name          string
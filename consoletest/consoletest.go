@@ -0,0 +1,117 @@
+// Package consoletest provides helpers to test commands registered on a
+// console.
+//
+//	tester := consoletest.New(cli).WithInputs("Lisa", "yes")
+//	result := tester.Execute("greet", "--loud")
+//
+//	assert.Equal(t, 0, result.ExitCode)
+//	result.AssertGolden(t, "greet", consoletest.StripANSI())
+package consoletest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/evolidev/console"
+	"github.com/evolidev/console/color"
+)
+
+type CommandTester struct {
+	console *console.Console
+	ctx     context.Context
+	stdin   io.Reader
+	inputs  []string
+	env     map[string]string
+}
+
+type Result struct {
+	Stdout   string
+	Stderr   string
+	Err      error
+	ExitCode int
+}
+
+func New(c *console.Console) *CommandTester {
+	return &CommandTester{
+		console: c,
+		ctx:     context.Background(),
+		env:     make(map[string]string),
+	}
+}
+
+func (t *CommandTester) WithContext(ctx context.Context) *CommandTester {
+	t.ctx = ctx
+	return t
+}
+
+func (t *CommandTester) WithStdin(stdin io.Reader) *CommandTester {
+	t.stdin = stdin
+	return t
+}
+
+// WithInputs scripts the answers to the prompts of the command. They are
+// read before the stdin.
+func (t *CommandTester) WithInputs(answers ...string) *CommandTester {
+	t.inputs = append(t.inputs, answers...)
+	return t
+}
+
+func (t *CommandTester) WithEnv(key string, value string) *CommandTester {
+	t.env[key] = value
+	return t
+}
+
+// Execute runs the command with the given arguments and captures its output.
+// The console is restored afterwards, so a tester can execute several times.
+func (t *CommandTester) Execute(args ...string) *Result {
+	c := t.console
+
+	output, errOutput, input, env := c.Output, c.ErrOutput, c.Input, c.Env
+	defer func() {
+		c.Output, c.ErrOutput, c.Input, c.Env = output, errOutput, input, env
+	}()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var readers []io.Reader
+	if len(t.inputs) > 0 {
+		readers = append(readers, strings.NewReader(strings.Join(t.inputs, "\n")+"\n"))
+	}
+	if t.stdin != nil {
+		readers = append(readers, t.stdin)
+	}
+
+	c.Output = stdout
+	c.ErrOutput = stderr
+	c.Input = io.MultiReader(readers...)
+	c.Env = t.environment(env)
+
+	err := c.Execute(t.ctx, args)
+
+	return &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Err:      err,
+		ExitCode: console.ExitCode(err),
+	}
+}
+
+func (t *CommandTester) environment(base map[string]string) map[string]string {
+	env := make(map[string]string, len(base)+len(t.env))
+	for key, value := range base {
+		env[key] = value
+	}
+	for key, value := range t.env {
+		env[key] = value
+	}
+
+	return env
+}
+
+// Display returns the stdout without escape sequences.
+func (r *Result) Display() string {
+	return color.Strip(r.Stdout)
+}
@@ -0,0 +1,92 @@
+package consoletest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/evolidev/console"
+	"github.com/evolidev/console/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConsole() *console.Console {
+	cli := console.New()
+	cli.EnableColors()
+
+	cli.AddHandler("greet {name?}", "Greet someone", func(ctx *console.Context) error {
+		name := ctx.GetArgument("name").String()
+		if name == "" {
+			name = ctx.Ask("What is your name?", "")
+		}
+
+		if !ctx.Confirm("Shout?", false) {
+			ctx.Printf("<info>Hello %s</info>\n", name)
+			return nil
+		}
+
+		ctx.Printf("<info>HELLO %s</info>\n", strings.ToUpper(name))
+		return nil
+	})
+
+	cli.AddHandler("fail", "Always fails", func(ctx *console.Context) error {
+		ctx.PrintErrln("something went wrong")
+		return console.Exit(3, errors.New("failed"))
+	})
+
+	cli.AddHandler("env", "Print the stage", func(ctx *console.Context) error {
+		ctx.Println(ctx.Getenv("STAGE"))
+		return nil
+	})
+
+	cli.AddCommand("legacy", "Old style command", func(cmd *parse.ParsedCommand) {})
+
+	return cli
+}
+
+func TestCommandTester(t *testing.T) {
+	t.Run("Scripted answers", func(t *testing.T) {
+		result := New(newConsole()).WithInputs("Lisa", "yes").Execute("greet")
+
+		assert.Nil(t, result.Err)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Contains(t, result.Display(), "What is your name?: \nShout? [y/N]: \nHELLO LISA\n")
+	})
+
+	t.Run("Stdin", func(t *testing.T) {
+		result := New(newConsole()).WithStdin(strings.NewReader("n\n")).Execute("greet", "Lisa")
+
+		assert.Contains(t, result.Display(), "Hello Lisa")
+	})
+
+	t.Run("Stderr and exit code", func(t *testing.T) {
+		result := New(newConsole()).Execute("fail")
+
+		assert.Equal(t, 3, result.ExitCode)
+		assert.EqualError(t, result.Err, "failed")
+		assert.Equal(t, "something went wrong\n", result.Stderr)
+		assert.Empty(t, result.Stdout)
+	})
+
+	t.Run("Environment", func(t *testing.T) {
+		cli := newConsole()
+		tester := New(cli).WithEnv("STAGE", "staging")
+
+		assert.Equal(t, "staging\n", tester.Execute("env").Stdout)
+		assert.Empty(t, cli.Getenv("STAGE"))
+	})
+
+	t.Run("Unknown command", func(t *testing.T) {
+		result := New(newConsole()).Execute("missing")
+
+		assert.ErrorIs(t, result.Err, console.ErrCommandNotFound)
+		assert.Equal(t, 1, result.ExitCode)
+		assert.Contains(t, result.Display(), "command does not exist")
+	})
+
+	t.Run("Golden file", func(t *testing.T) {
+		result := New(newConsole()).Execute()
+
+		result.AssertGolden(t, "list", StripANSI())
+	})
+}
@@ -0,0 +1,82 @@
+package consoletest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evolidev/console/color"
+)
+
+// UpdateEnv is the environment variable which rewrites the golden files
+// with the actual output instead of comparing them, e.g.
+// UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "UPDATE_GOLDEN"
+
+type goldenOptions struct {
+	stripANSI bool
+	dir       string
+}
+
+type GoldenOption func(o *goldenOptions)
+
+// StripANSI removes the escape sequences before comparing the output.
+func StripANSI() GoldenOption {
+	return func(o *goldenOptions) {
+		o.stripANSI = true
+	}
+}
+
+// GoldenDir changes the directory of the golden files, testdata by default.
+func GoldenDir(dir string) GoldenOption {
+	return func(o *goldenOptions) {
+		o.dir = dir
+	}
+}
+
+// AssertGolden compares actual with the content of <dir>/<name>.golden.
+func AssertGolden(t testing.TB, name string, actual string, opts ...GoldenOption) bool {
+	t.Helper()
+
+	options := &goldenOptions{dir: "testdata"}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.stripANSI {
+		actual = color.Strip(actual)
+	}
+
+	path := filepath.Join(options.dir, name+".golden")
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("create golden directory: %s", err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("update golden file: %s", err)
+		}
+
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("read golden file: %s (run with %s=1 to create it)", err, UpdateEnv)
+		return false
+	}
+
+	if string(expected) != actual {
+		t.Errorf("output does not match %s\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+		return false
+	}
+
+	return true
+}
+
+// AssertGolden compares the stdout of the command with a golden file.
+func (r *Result) AssertGolden(t testing.TB, name string, opts ...GoldenOption) bool {
+	t.Helper()
+
+	return AssertGolden(t, name, r.Stdout, opts...)
+}
@@ -0,0 +1,279 @@
+// Package container resolves the dependencies of command handlers. Services
+// are bound by their constructors, whose parameters are resolved from the
+// container as well.
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotBound       = errors.New("no binding")
+	ErrCycle          = errors.New("dependency cycle")
+	ErrInvalidBinding = errors.New("invalid binding")
+)
+
+type Lifetime int
+
+const (
+	// Singleton services are constructed once per container.
+	Singleton Lifetime = iota
+	// PerInvocation services are constructed once per scope, i.e. once for
+	// every command which is run.
+	PerInvocation
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+type binding struct {
+	lifetime    Lifetime
+	constructor reflect.Value
+
+	mu       sync.Mutex
+	instance reflect.Value
+}
+
+type Container struct {
+	mu       sync.RWMutex
+	bindings map[reflect.Type]*binding
+}
+
+func New() *Container {
+	return &Container{bindings: make(map[reflect.Type]*binding)}
+}
+
+// Singleton binds the result of constructor, which has the form
+// func(deps...) T or func(deps...) (T, error), as a singleton of type T.
+func (c *Container) Singleton(constructor any) error {
+	return c.Bind(Singleton, constructor)
+}
+
+func (c *Container) PerInvocation(constructor any) error {
+	return c.Bind(PerInvocation, constructor)
+}
+
+// Instance binds an existing value by its type.
+func (c *Container) Instance(value any) {
+	v := reflect.ValueOf(value)
+
+	c.mu.Lock()
+	c.bindings[v.Type()] = &binding{lifetime: Singleton, instance: v}
+	c.mu.Unlock()
+}
+
+// Bind binds a constructor with the given lifetime. A later binding of the
+// same type replaces the earlier one.
+func (c *Container) Bind(lifetime Lifetime, constructor any) error {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return fmt.Errorf("%w: constructor must be a function, got %T", ErrInvalidBinding, constructor)
+	}
+
+	t := fn.Type()
+	if t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) {
+		return fmt.Errorf("%w: constructor %s must return T or (T, error)", ErrInvalidBinding, funcName(fn))
+	}
+
+	c.mu.Lock()
+	c.bindings[t.Out(0)] = &binding{lifetime: lifetime, constructor: fn}
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *Container) Has(t reflect.Type) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.bindings[t]
+	return ok
+}
+
+// Scope returns a scope for one invocation. Values which are not bound in
+// the container, like the context of a command, can be passed to it.
+func (c *Container) Scope(values ...any) *Scope {
+	scope := &Scope{container: c, instances: make(map[reflect.Type]reflect.Value)}
+
+	for _, value := range values {
+		if value != nil {
+			scope.values = append(scope.values, reflect.ValueOf(value))
+		}
+	}
+
+	return scope
+}
+
+// Call calls fn with its parameters resolved from the container and returns
+// the error fn returns, if any.
+func (c *Container) Call(fn any, values ...any) error {
+	return c.Scope(values...).Call(fn)
+}
+
+// Scope caches the per-invocation services of one invocation.
+type Scope struct {
+	container *Container
+	values    []reflect.Value
+
+	mu        sync.Mutex
+	instances map[reflect.Type]reflect.Value
+}
+
+// Resolve returns the value for t.
+func (s *Scope) Resolve(t reflect.Type) (reflect.Value, error) {
+	return s.resolve(t, nil)
+}
+
+// Call calls fn with its parameters resolved and returns the error fn
+// returns, if any. Missing bindings are reported before fn is called.
+func (s *Scope) Call(fn any) error {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func {
+		return fmt.Errorf("%w: expected a function, got %T", ErrInvalidBinding, fn)
+	}
+
+	args, err := s.arguments(f, nil)
+	if err != nil {
+		return err
+	}
+
+	out := f.Call(args)
+	if len(out) > 0 && out[len(out)-1].Type() == errorType && !out[len(out)-1].IsNil() {
+		return out[len(out)-1].Interface().(error)
+	}
+
+	return nil
+}
+
+func (s *Scope) arguments(fn reflect.Value, path []reflect.Type) ([]reflect.Value, error) {
+	t := fn.Type()
+	if t.IsVariadic() {
+		return nil, fmt.Errorf("%w: %s is variadic", ErrInvalidBinding, funcName(fn))
+	}
+
+	args := make([]reflect.Value, t.NumIn())
+	for i := range args {
+		arg, err := s.resolve(t.In(i), path)
+		if err != nil {
+			if errors.Is(err, ErrNotBound) && len(path) == 0 {
+				return nil, fmt.Errorf("%w (parameter %d of %s)", err, i+1, funcName(fn))
+			}
+
+			return nil, err
+		}
+
+		args[i] = arg
+	}
+
+	return args, nil
+}
+
+// resolve prefers a scope value of exactly type t, then a binding of t and
+// then a scope value which implements t, so an interface bound explicitly is
+// not shadowed by a value which happens to implement it, e.g. the context.
+func (s *Scope) resolve(t reflect.Type, path []reflect.Type) (reflect.Value, error) {
+	for _, value := range s.values {
+		if value.Type() == t {
+			return value, nil
+		}
+	}
+
+	s.container.mu.RLock()
+	b, ok := s.container.bindings[t]
+	s.container.mu.RUnlock()
+
+	if !ok {
+		if t.Kind() == reflect.Interface {
+			for _, value := range s.values {
+				if value.Type().Implements(t) {
+					return value, nil
+				}
+			}
+		}
+
+		if len(path) > 0 {
+			return reflect.Value{}, fmt.Errorf("%w for %s, needed by %s", ErrNotBound, t, formatPath(path))
+		}
+
+		return reflect.Value{}, fmt.Errorf("%w for %s", ErrNotBound, t)
+	}
+
+	for _, seen := range path {
+		if seen == t {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrCycle, formatPath(append(path, t)))
+		}
+	}
+	path = append(path, t)
+
+	if b.lifetime == PerInvocation {
+		s.mu.Lock()
+		instance, ok := s.instances[t]
+		s.mu.Unlock()
+
+		if ok {
+			return instance, nil
+		}
+
+		instance, err := s.construct(b.constructor, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		s.mu.Lock()
+		s.instances[t] = instance
+		s.mu.Unlock()
+
+		return instance, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.instance.IsValid() {
+		// singletons must not depend on values of a single invocation
+		root := &Scope{container: s.container, instances: make(map[reflect.Type]reflect.Value)}
+
+		instance, err := root.construct(b.constructor, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		b.instance = instance
+	}
+
+	return b.instance, nil
+}
+
+func (s *Scope) construct(constructor reflect.Value, path []reflect.Type) (reflect.Value, error) {
+	args, err := s.arguments(constructor, path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	out := constructor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("construct %s: %w", out[0].Type(), out[1].Interface().(error))
+	}
+
+	return out[0], nil
+}
+
+func formatPath(path []reflect.Type) string {
+	names := make([]string, len(path))
+	for i, t := range path {
+		names[i] = t.String()
+	}
+
+	return strings.Join(names, " -> ")
+}
+
+func funcName(fn reflect.Value) string {
+	if f := runtime.FuncForPC(fn.Pointer()); f != nil {
+		return f.Name()
+	}
+
+	return fn.Type().String()
+}
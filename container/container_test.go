@@ -0,0 +1,107 @@
+package container
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type config struct {
+	dsn string
+}
+
+type database struct {
+	config *config
+}
+
+type repository struct {
+	db *database
+}
+
+type request struct{}
+
+func TestResolve(t *testing.T) {
+	databases := 0
+
+	c := New()
+	c.Instance(&config{dsn: "memory"})
+	assert.Nil(t, c.Singleton(func(cfg *config) (*database, error) {
+		databases++
+		return &database{config: cfg}, nil
+	}))
+	assert.Nil(t, c.PerInvocation(func(db *database) *repository {
+		return &repository{db: db}
+	}))
+
+	var first, second *repository
+	assert.Nil(t, c.Call(func(a *repository, b *repository) {
+		assert.Same(t, a, b)
+		first = a
+	}))
+	assert.Nil(t, c.Call(func(r *repository) {
+		second = r
+	}))
+
+	assert.NotSame(t, first, second)
+	assert.Same(t, first.db, second.db)
+	assert.Equal(t, "memory", first.db.config.dsn)
+	assert.Equal(t, 1, databases)
+}
+
+func TestScopeValues(t *testing.T) {
+	c := New()
+	r := &request{}
+
+	err := c.Call(func(got *request, s error) error {
+		assert.Same(t, r, got)
+		return s
+	}, r, errors.New("passed"))
+
+	assert.EqualError(t, err, "passed")
+}
+
+type named interface {
+	Name() string
+}
+
+type name string
+
+func (n name) Name() string {
+	return string(n)
+}
+
+func TestBindingsPrecedeInterfaceValues(t *testing.T) {
+	c := New()
+	assert.Nil(t, c.Singleton(func() named {
+		return name("bound")
+	}))
+
+	err := c.Call(func(n named, exact name) {
+		assert.Equal(t, "bound", n.Name())
+		assert.Equal(t, "passed", exact.Name())
+	}, name("passed"))
+	assert.Nil(t, err)
+}
+
+func TestErrors(t *testing.T) {
+	c := New()
+	assert.Nil(t, c.Singleton(func(cfg *config) *database { return &database{config: cfg} }))
+
+	err := c.Call(func(db *database) {})
+	assert.True(t, errors.Is(err, ErrNotBound))
+	assert.Contains(t, err.Error(), "no binding for *container.config, needed by *container.database")
+
+	assert.Nil(t, c.Singleton(func(db *database) *config { return nil }))
+	_, err = c.Scope().Resolve(reflect.TypeOf(&database{}))
+	assert.True(t, errors.Is(err, ErrCycle))
+	assert.EqualError(t, err, "dependency cycle: *container.database -> *container.config -> *container.database")
+
+	assert.Nil(t, c.Singleton(func() (*config, error) { return nil, errors.New("unreachable") }))
+	err = c.Call(func(cfg *config) {})
+	assert.EqualError(t, err, "construct *container.config: unreachable")
+
+	assert.True(t, errors.Is(c.Singleton("nope"), ErrInvalidBinding))
+	assert.True(t, errors.Is(c.Singleton(func() {}), ErrInvalidBinding))
+}
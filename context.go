@@ -0,0 +1,64 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/evolidev/console/parse"
+)
+
+var ErrCommandNotFound = errors.New("command does not exist")
+
+// Context is passed to command handlers. It gives access to the parsed
+// arguments and options, the console the command runs on and the context of
+// the invocation.
+type Context struct {
+	context.Context
+	*parse.ParsedCommand
+	*Console
+
+	// Args are the arguments the command was called with, starting with the
+	// name of the command.
+	Args []string
+
+	// rawArgs are the arguments after the name of the command, before the
+	// console removed its global options
+	rawArgs []string
+}
+
+// ExitError lets a handler choose the exit code of the application.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+func Exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the exit code for the error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return 1
+}
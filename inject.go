@@ -0,0 +1,33 @@
+package console
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Inject turns a function with typed parameters, e.g.
+//
+//	func(ctx *console.Context, db *sql.DB, cfg *Config) error
+//
+// into a handler. The parameters are resolved from the container of the
+// console when the command runs. Besides the bound services the *Context,
+// context.Context, *parse.ParsedCommand and *Console can be requested.
+// Inject panics when fn is not a function returning nothing or an error.
+func Inject(fn any) func(ctx *Context) error {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func || t.NumOut() > 1 || (t.NumOut() == 1 && t.Out(0) != errorType) {
+		panic(fmt.Sprintf("console: cannot inject %T, expected a function returning an error", fn))
+	}
+
+	return func(ctx *Context) error {
+		return ctx.Container.Call(fn, ctx, ctx.ParsedCommand, ctx.Console)
+	}
+}
+
+// AddFunc registers a command whose handler parameters are injected, see
+// Inject.
+func (c *Console) AddFunc(name string, description string, fn any) *Command {
+	return c.AddHandler(name, description, Inject(fn))
+}
@@ -0,0 +1,82 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/evolidev/console/container"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Greeting string
+}
+
+type testSession struct {
+	id int
+}
+
+func TestInject(t *testing.T) {
+	sessions := 0
+
+	cli := New()
+	cli.DisableColors()
+	cli.Container.Instance(&testConfig{Greeting: "hello"})
+	assert.Nil(t, cli.Container.PerInvocation(func() *testSession {
+		sessions++
+		return &testSession{id: sessions}
+	}))
+
+	var out strings.Builder
+	cli.Output = &out
+	cli.AddFunc("greet {name}", "Greet someone", func(ctx *Context, cfg *testConfig, session *testSession, again *testSession, c context.Context) error {
+		assert.Same(t, session, again)
+		assert.NotNil(t, c)
+		ctx.Println(cfg.Greeting + " " + ctx.GetArgument("name").String())
+		return nil
+	})
+
+	assert.Nil(t, cli.Execute(context.Background(), []string{"greet", "world"}))
+	assert.Nil(t, cli.Execute(context.Background(), []string{"greet", "again"}))
+	assert.Equal(t, "hello world\nhello again\n", out.String())
+	assert.Equal(t, 2, sessions)
+}
+
+type testPrinter interface {
+	Println(args ...any)
+}
+
+type nopPrinter struct{}
+
+func (p *nopPrinter) Println(args ...any) {}
+
+func TestInjectBindingImplementedByContext(t *testing.T) {
+	printer := &nopPrinter{}
+
+	cli := New()
+	assert.Nil(t, cli.Container.Singleton(func() testPrinter {
+		return printer
+	}))
+
+	cli.AddFunc("greet", "", func(p testPrinter, c context.Context) {
+		assert.Same(t, printer, p)
+		assert.NotNil(t, c)
+	})
+
+	assert.Nil(t, cli.Execute(context.Background(), []string{"greet"}))
+}
+
+func TestInjectMissingBinding(t *testing.T) {
+	cli := New()
+	cli.AddFunc("greet", "", func(cfg *testConfig) {})
+
+	err := cli.Execute(context.Background(), []string{"greet"})
+	assert.True(t, errors.Is(err, container.ErrNotBound))
+	assert.Contains(t, err.Error(), "no binding for *console.testConfig (parameter 1 of")
+
+	assert.Panics(t, func() {
+		Inject(func() int { return 0 })
+	})
+}
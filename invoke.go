@@ -0,0 +1,49 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Invoke runs a registered command with structured arguments and options,
+// e.g. from another command. The input is validated against the definition
+// of the command and the error of the command is returned.
+func (c *Console) Invoke(ctx context.Context, name string, args map[string]any, opts map[string]any) error {
+	return c.invoke(ctx, c, name, args, opts)
+}
+
+// InvokeTo is like Invoke but writes the output of the command to output,
+// e.g. a buffer. Only output written through the console of the context is
+// captured.
+func (c *Console) InvokeTo(ctx context.Context, output io.Writer, name string, args map[string]any, opts map[string]any) error {
+	return c.invoke(ctx, c.withOutput(output), name, args, opts)
+}
+
+func (c *Console) invoke(ctx context.Context, target *Console, name string, args map[string]any, opts map[string]any) error {
+	if err := c.Boot(); err != nil {
+		return err
+	}
+
+	cmd, ok := c.Commands[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCommandNotFound, name)
+	}
+
+	parsed, err := cmd.Spec().Bind(args, opts)
+	if err != nil {
+		return err
+	}
+
+	return target.execute(ctx, cmd, parsed, []string{name}, nil)
+}
+
+// withOutput returns a copy of the console which shares the commands and
+// settings but writes to another output.
+func (c *Console) withOutput(output io.Writer) *Console {
+	derived := *c
+	derived.Output = output
+	derived.progress = &progressArea{console: &derived}
+
+	return &derived
+}
@@ -0,0 +1,90 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evolidev/console/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoke(t *testing.T) {
+	cli := New()
+	cli.DisableColors()
+	cli.SetOutput(&bytes.Buffer{})
+
+	var migrated []string
+	cli.AddHandler("migrate {database} {tables?*} {--force} {--S|step=1}", "Run migrations", func(ctx *Context) error {
+		if !ctx.GetOption("force").Bool() {
+			return errors.New("use --force in production")
+		}
+
+		migrated = ctx.GetArgument("tables").Strings()
+		ctx.Println("migrated", ctx.GetArgument("database").String(), "in steps of", ctx.GetOption("step").Integer())
+		return nil
+	})
+
+	cli.AddHandler("deploy", "Deploy the application", func(ctx *Context) error {
+		return ctx.Invoke(ctx, "migrate", map[string]any{"database": "main"}, map[string]any{"force": true})
+	})
+
+	t.Run("Invoke with structured input", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		err := cli.InvokeTo(context.Background(), out, "migrate",
+			map[string]any{"database": "main", "tables": []string{"users", "posts"}},
+			map[string]any{"force": true, "S": 5},
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"users", "posts"}, migrated)
+		assert.Equal(t, "migrated main in steps of 5\n", out.String())
+	})
+
+	t.Run("Invoke from another command", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		err := cli.InvokeTo(context.Background(), out, "deploy", nil, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "migrated main in steps of 1\n", out.String())
+	})
+
+	t.Run("The error of the command is returned", func(t *testing.T) {
+		err := cli.Invoke(context.Background(), "migrate", map[string]any{"database": "main"}, nil)
+
+		assert.EqualError(t, err, "use --force in production")
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		tests := []struct {
+			args  map[string]any
+			opts  map[string]any
+			error string
+		}{
+			{nil, nil, `invalid input: missing argument "database" for migrate`},
+			{map[string]any{"database": "main", "table": "users"}, nil, `invalid input: unknown argument "table" for migrate`},
+			{map[string]any{"database": "main"}, map[string]any{"pretend": true}, `invalid input: unknown option "pretend" for migrate`},
+			{map[string]any{"database": "main"}, map[string]any{"force": "maybe"}, `invalid input: option "force" is a flag: strconv.ParseBool: parsing "maybe": invalid syntax`},
+		}
+
+		for _, test := range tests {
+			err := cli.Invoke(context.Background(), "migrate", test.args, test.opts)
+
+			assert.ErrorIs(t, err, parse.ErrInvalidInput)
+			assert.EqualError(t, err, test.error)
+		}
+	})
+
+	t.Run("Unknown command", func(t *testing.T) {
+		out := cli.Output.(*bytes.Buffer)
+		out.Reset()
+
+		err := cli.Invoke(context.Background(), "cache:clear", nil, nil)
+
+		assert.ErrorIs(t, err, ErrCommandNotFound)
+		assert.Empty(t, out.String())
+	})
+}
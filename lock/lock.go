@@ -0,0 +1,195 @@
+// Package lock provides advisory file locks between processes. The lock
+// file contains the PID of its holder, so a locked error can tell who holds
+// it and PID files of crashed processes are recognized as stale.
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrLocked = errors.New("locked")
+
+// PollInterval is how often Acquire retries while waiting for a lock.
+var PollInterval = 100 * time.Millisecond
+
+// LockedError is returned when another process holds the lock.
+type LockedError struct {
+	Path string
+	PID  int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("%s is locked by another process", e.Path)
+	}
+
+	return fmt.Sprintf("%s is locked by process %d", e.Path, e.PID)
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+type Lock struct {
+	Path string
+	// Timeout is how long Acquire waits for the lock to be released, by
+	// default it fails immediately.
+	Timeout time.Duration
+
+	file *os.File
+}
+
+func New(path string) *Lock {
+	return &Lock{Path: path}
+}
+
+// Acquire takes the lock, waiting up to Timeout while another process holds
+// it. The last LockedError is returned when the timeout expires.
+func (l *Lock) Acquire(ctx context.Context) error {
+	var deadline <-chan time.Time
+	if l.Timeout > 0 {
+		timer := time.NewTimer(l.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		err := l.TryAcquire()
+		if !errors.Is(err, ErrLocked) || deadline == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return err
+		case <-time.After(PollInterval):
+		}
+	}
+}
+
+// TryAcquire takes the lock without waiting. The lock is held until Release
+// is called or the process exits.
+func (l *Lock) TryAcquire() error {
+	if l.file != nil {
+		return fmt.Errorf("lock: %s is already acquired", l.Path)
+	}
+
+	f, err := l.open()
+	if err != nil {
+		return err
+	}
+
+	// a stale PID of a crashed holder is simply overwritten
+	if err := writePID(f); err != nil {
+		unlockFile(f)
+		f.Close()
+		return err
+	}
+
+	l.file = f
+
+	return nil
+}
+
+// open opens and locks the lock file. The previous holder removes the file
+// when it releases the lock, so a locked file which is no longer at Path is
+// opened again.
+func (l *Lock) open() (*os.File, error) {
+	for {
+		f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := lockFile(f); err != nil {
+			f.Close()
+			if errors.Is(err, errWouldBlock) {
+				return nil, &LockedError{Path: l.Path, PID: Holder(l.Path)}
+			}
+
+			return nil, err
+		}
+
+		opened, err := f.Stat()
+		if err != nil {
+			unlockFile(f)
+			f.Close()
+			return nil, err
+		}
+
+		current, err := os.Stat(l.Path)
+		if err == nil && os.SameFile(opened, current) {
+			return f, nil
+		}
+
+		unlockFile(f)
+		f.Close()
+	}
+}
+
+// Release releases the lock and removes the lock file.
+func (l *Lock) Release() error {
+	if l.file == nil {
+		return nil
+	}
+
+	f := l.file
+	l.file = nil
+
+	// the file is removed while it is still locked, so no other process
+	// can lock the file which is about to disappear
+	os.Remove(l.Path)
+	unlockFile(f)
+
+	return f.Close()
+}
+
+// Holder returns the PID of the process which holds the lock or wrote the
+// PID file at path. It returns 0 when there is no such file or the process
+// is no longer running, i.e. the file is stale.
+func Holder(path string) int {
+	pid, err := ReadPID(path)
+	if err != nil || !processRunning(pid) {
+		return 0
+	}
+
+	return pid
+}
+
+// ReadPID reads the PID stored in a lock or PID file.
+func ReadPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("lock: invalid PID in %s", path)
+	}
+
+	return pid, nil
+}
+
+// WritePID writes the PID of the current process to a PID file.
+func WritePID(path string) error {
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0644)
+}
+
+func writePID(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	_, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+
+	return err
+}
@@ -0,0 +1,81 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrate.lock")
+
+	first := New(path)
+	assert.Nil(t, first.TryAcquire())
+	assert.Equal(t, os.Getpid(), Holder(path))
+
+	second := New(path)
+	err := second.TryAcquire()
+
+	var locked *LockedError
+	assert.True(t, errors.As(err, &locked))
+	assert.True(t, errors.Is(err, ErrLocked))
+	assert.Equal(t, os.Getpid(), locked.PID)
+	assert.Equal(t, fmt.Sprintf("%s is locked by process %d", path, os.Getpid()), err.Error())
+
+	assert.Nil(t, first.Release())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, second.TryAcquire())
+	assert.Nil(t, second.Release())
+}
+
+func TestAcquireTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrate.lock")
+
+	holder := New(path)
+	assert.Nil(t, holder.TryAcquire())
+
+	waiting := New(path)
+	waiting.Timeout = 50 * time.Millisecond
+	assert.True(t, errors.Is(waiting.Acquire(context.Background()), ErrLocked))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		holder.Release()
+	}()
+
+	waiting.Timeout = time.Second
+	assert.Nil(t, waiting.Acquire(context.Background()))
+	assert.Nil(t, waiting.Release())
+}
+
+func TestStalePID(t *testing.T) {
+	cmd := exec.Command("go", "version")
+	if err := cmd.Run(); err != nil {
+		t.Skip(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "serve.pid")
+	assert.Nil(t, os.WriteFile(path, []byte(fmt.Sprint(cmd.Process.Pid)), 0644))
+
+	pid, err := ReadPID(path)
+	assert.Nil(t, err)
+	assert.Equal(t, cmd.Process.Pid, pid)
+	assert.Equal(t, 0, Holder(path))
+
+	// the stale PID file doesn't prevent locking
+	l := New(path)
+	assert.Nil(t, l.TryAcquire())
+	assert.Equal(t, os.Getpid(), Holder(path))
+	assert.Nil(t, l.Release())
+
+	assert.Equal(t, 0, Holder(filepath.Join(t.TempDir(), "missing.pid")))
+}
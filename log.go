@@ -0,0 +1,271 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/evolidev/console/parse"
+)
+
+type Verbosity int
+
+const (
+	VerbosityNormal Verbosity = iota
+	VerbosityVerbose
+	VerbosityVeryVerbose
+	VerbosityDebug
+)
+
+type LogFormat int
+
+const (
+	LogText LogFormat = iota
+	LogJSON
+)
+
+// LevelTrace is shown with -vv, below slog.LevelDebug.
+const LevelTrace = slog.LevelDebug - 4
+
+func (c *Console) SetVerbosity(verbosity Verbosity) {
+	c.Verbosity = verbosity
+}
+
+func (c *Console) SetLogFormat(format LogFormat) {
+	c.LogFormat = format
+}
+
+// LogLevel returns the lowest level which is logged for the current
+// verbosity, -v shows debug messages and -vvv everything.
+func (c *Console) LogLevel() slog.Level {
+	if c.Quiet {
+		return slog.Level(math.MaxInt32)
+	}
+
+	switch {
+	case c.Verbosity >= VerbosityDebug:
+		return slog.Level(math.MinInt32)
+	case c.Verbosity == VerbosityVeryVerbose:
+		return LevelTrace
+	case c.Verbosity == VerbosityVerbose:
+		return slog.LevelDebug
+	}
+
+	return slog.LevelInfo
+}
+
+// Logger returns a logger which writes to the output of the console.
+func (c *Console) Logger() *slog.Logger {
+	return slog.New(c.LogHandler())
+}
+
+// LogHandler renders log records with the colors of the console, or as JSON
+// lines when the log format is LogJSON.
+func (c *Console) LogHandler() slog.Handler {
+	return &LogHandler{
+		console: c,
+		json:    slog.NewJSONHandler(logWriter{c}, &slog.HandlerOptions{Level: logLevel{c}}),
+	}
+}
+
+type LogHandler struct {
+	console *Console
+	attrs   []slog.Attr
+	groups  []string
+	json    slog.Handler
+}
+
+func (h *LogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.console.LogLevel()
+}
+
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.console.LogFormat == LogJSON {
+		return h.json.Handle(ctx, record)
+	}
+
+	var b strings.Builder
+
+	b.WriteString(h.console.Text(245, record.Time.Format(time.TimeOnly)))
+	b.WriteString(" ")
+	b.WriteString(h.level(record.Level))
+	b.WriteString(" ")
+	b.WriteString(record.Message)
+
+	prefix := ""
+	if len(h.groups) > 0 {
+		prefix = strings.Join(h.groups, ".") + "."
+	}
+
+	for _, attr := range h.attrs {
+		h.appendAttr(&b, "", attr)
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		h.appendAttr(&b, prefix, attr)
+		return true
+	})
+
+	b.WriteString("\n")
+
+	h.console.area().print(b.String())
+
+	return nil
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := h.clone()
+
+	prefix := ""
+	if len(h.groups) > 0 {
+		prefix = strings.Join(h.groups, ".") + "."
+	}
+
+	for _, attr := range attrs {
+		attr.Key = prefix + attr.Key
+		handler.attrs = append(handler.attrs, attr)
+	}
+
+	handler.json = h.json.WithAttrs(attrs)
+
+	return handler
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handler := h.clone()
+	handler.groups = append(handler.groups, name)
+
+	handler.json = h.json.WithGroup(name)
+
+	return handler
+}
+
+func (h *LogHandler) clone() *LogHandler {
+	return &LogHandler{
+		console: h.console,
+		attrs:   append([]slog.Attr{}, h.attrs...),
+		groups:  append([]string{}, h.groups...),
+		json:    h.json,
+	}
+}
+
+func (h *LogHandler) appendAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+
+		for _, child := range attr.Value.Group() {
+			h.appendAttr(b, prefix, child)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if strings.ContainsAny(value, " \t\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+
+	b.WriteString(" ")
+	b.WriteString(h.console.Text(245, prefix+attr.Key+"="))
+	b.WriteString(value)
+}
+
+func (h *LogHandler) level(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return h.console.Text(203, "ERROR")
+	case level >= slog.LevelWarn:
+		return h.console.Text(215, "WARN ")
+	case level >= slog.LevelInfo:
+		return h.console.Text(114, "INFO ")
+	case level >= slog.LevelDebug:
+		return h.console.Text(111, "DEBUG")
+	}
+
+	return h.console.Text(245, "TRACE")
+}
+
+// logLevel follows the verbosity of the console, even when it changes after
+// the logger was created.
+type logLevel struct {
+	console *Console
+}
+
+func (l logLevel) Level() slog.Level {
+	return l.console.LogLevel()
+}
+
+type logWriter struct {
+	console *Console
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w.console.area().print(string(p))
+	return len(p), nil
+}
+
+// parseVerbosity removes the global verbosity options from args and applies
+// them to the console. Arguments after "--" and options the command declares
+// itself, e.g. {--v|version}, are left to the command.
+func (c *Console) parseVerbosity(args []string) []string {
+	var def *parse.Definition
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if verbosityOption(arg) {
+			continue
+		}
+		if cmd, ok := c.Commands[arg]; ok {
+			def = cmd.Spec()
+		}
+		break
+	}
+
+	var remaining []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(remaining, args[i:]...)
+		}
+
+		if !verbosityOption(arg) || (def != nil && def.Option(strings.TrimLeft(arg, "-")) != nil) {
+			remaining = append(remaining, arg)
+			continue
+		}
+
+		switch arg {
+		case "-q", "--quiet":
+			c.Quiet = true
+		case "-v", "--verbose":
+			c.Verbosity = VerbosityVerbose
+		case "-vv":
+			c.Verbosity = VerbosityVeryVerbose
+		case "-vvv":
+			c.Verbosity = VerbosityDebug
+		}
+	}
+
+	return remaining
+}
+
+func verbosityOption(arg string) bool {
+	switch arg {
+	case "-q", "--quiet", "-v", "--verbose", "-vv", "-vvv":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package console
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/evolidev/console/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	t.Run("Debug messages are shown with -v", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+
+		out := &strings.Builder{}
+		cli.SetOutput(out)
+
+		logger := cli.Logger()
+		cli.AddCommand("migrate", "Run migrations", func(cmd *parse.ParsedCommand) {
+			logger.Debug("connecting", "host", "db")
+			logger.Info("migrated", "count", 3)
+		})
+
+		cli.Call([]string{"migrate"})
+		assert.NotContains(t, out.String(), "connecting")
+		assert.Contains(t, out.String(), "INFO  migrated count=3")
+
+		out.Reset()
+		cli.Call([]string{"migrate", "-v"})
+		assert.Contains(t, out.String(), "DEBUG connecting host=db")
+	})
+
+	t.Run("Quiet consoles do not log", func(t *testing.T) {
+		cli := New()
+
+		out := &strings.Builder{}
+		cli.SetOutput(out)
+
+		cli.AddCommand("migrate", "Run migrations", func(cmd *parse.ParsedCommand) {
+			cli.Logger().Error("failed")
+		})
+
+		cli.Call([]string{"migrate", "--quiet"})
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("Attributes and groups", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+
+		out := &strings.Builder{}
+		cli.SetOutput(out)
+
+		cli.Logger().With("command", "deploy").WithGroup("server").Warn("slow response", "url", "/a b")
+		assert.Contains(t, out.String(), `WARN  slow response command=deploy server.url="/a b"`)
+	})
+
+	t.Run("JSON lines", func(t *testing.T) {
+		cli := New()
+		cli.SetLogFormat(LogJSON)
+		cli.SetVerbosity(VerbosityVerbose)
+
+		out := &strings.Builder{}
+		cli.SetOutput(out)
+
+		cli.Logger().With("command", "deploy").Debug("done", "count", 2)
+
+		var record map[string]any
+		assert.Nil(t, json.Unmarshal([]byte(out.String()), &record))
+		assert.Equal(t, "DEBUG", record["level"])
+		assert.Equal(t, "done", record["msg"])
+		assert.Equal(t, "deploy", record["command"])
+		assert.Equal(t, float64(2), record["count"])
+	})
+}
+
+func TestParseVerbosity(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		remaining []string
+		verbosity Verbosity
+		quiet     bool
+	}{
+		{"global options", []string{"-v", "deploy", "-q"}, []string{"deploy"}, VerbosityVerbose, true},
+		{"most verbose", []string{"deploy", "-vvv"}, []string{"deploy"}, VerbosityDebug, false},
+		{"after --", []string{"deploy", "--", "-v", "--quiet"}, []string{"deploy", "--", "-v", "--quiet"}, VerbosityNormal, false},
+		{"declared by the command", []string{"release", "-v", "--verbose"}, []string{"release", "-v"}, VerbosityVerbose, false},
+		{"not declared by the command", []string{"deploy", "-v"}, []string{"deploy"}, VerbosityVerbose, false},
+	}
+
+	for _, test := range tests {
+		cli := New()
+		cli.AddCommand("deploy {env?}", "Deploy", func(cmd *parse.ParsedCommand) {})
+		cli.AddCommand("release {--v|version}", "Release", func(cmd *parse.ParsedCommand) {})
+
+		assert.Equal(t, test.remaining, cli.parseVerbosity(test.args), test.name)
+		assert.Equal(t, test.verbosity, cli.Verbosity, test.name)
+		assert.Equal(t, test.quiet, cli.Quiet, test.name)
+	}
+
+	t.Run("The command receives its own option", func(t *testing.T) {
+		cli := New()
+		cli.SetOutput(&strings.Builder{})
+
+		version := false
+		cli.AddCommand("release {--v|version}", "Release", func(cmd *parse.ParsedCommand) {
+			version = cmd.HasOption("version")
+		})
+
+		cli.Call([]string{"release", "-v"})
+		assert.True(t, version)
+		assert.Equal(t, VerbosityNormal, cli.Verbosity)
+	})
+}
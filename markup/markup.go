@@ -0,0 +1,231 @@
+// Package markup formats text with inline style tags like
+// "<info>Deployed</info> <fg=#ff0 options=bold>v1.2</>".
+//
+// A tag either refers to a named style or defines a style inline with the
+// fg, bg, options and href keys, separated by spaces or semicolons. Tags
+// are closed with </> or with the name of the innermost open tag, other
+// closing tags are literal text. Unknown tags are kept as they are and a
+// literal tag can be escaped with a backslash: \<info>.
+package markup
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/evolidev/console/color"
+)
+
+type Formatter struct {
+	styles map[string]color.Style
+}
+
+// New returns a formatter with the default styles info, comment, question,
+// error, warning and success.
+func New() *Formatter {
+	f := &Formatter{styles: make(map[string]color.Style)}
+
+	f.SetStyle("info", color.NewStyle().Foreground(named("green")))
+	f.SetStyle("comment", color.NewStyle().Foreground(named("yellow")))
+	f.SetStyle("question", color.NewStyle().Foreground(named("black")).Background(named("cyan")))
+	f.SetStyle("error", color.NewStyle().Foreground(named("bright-white")).Background(named("red")))
+	f.SetStyle("warning", color.NewStyle().Foreground(named("black")).Background(named("yellow")))
+	f.SetStyle("success", color.NewStyle().Foreground(named("bright-green")).Bold())
+
+	return f
+}
+
+func (f *Formatter) SetStyle(name string, style color.Style) {
+	f.styles[strings.ToLower(name)] = style
+}
+
+func (f *Formatter) Style(name string) (color.Style, bool) {
+	style, ok := f.styles[strings.ToLower(name)]
+	return style, ok
+}
+
+// Format replaces the tags in text with the escape sequences of the given
+// profile. With color.NoColor the tags are removed.
+func (f *Formatter) Format(p color.Profile, text string) string {
+	if !strings.ContainsRune(text, '<') {
+		return text
+	}
+
+	var out strings.Builder
+	var segment strings.Builder
+	var stack []openTag
+
+	flush := func() {
+		if segment.Len() == 0 {
+			return
+		}
+
+		if len(stack) == 0 {
+			out.WriteString(segment.String())
+		} else {
+			out.WriteString(stack[len(stack)-1].style.Render(p, segment.String()))
+		}
+
+		segment.Reset()
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if c == '\\' && i+1 < len(text) && text[i+1] == '<' {
+			segment.WriteByte('<')
+			i++
+			continue
+		}
+
+		if c != '<' {
+			segment.WriteByte(c)
+			continue
+		}
+
+		end := strings.IndexAny(text[i+1:], "<>")
+		if end < 0 || text[i+1+end] != '>' {
+			segment.WriteByte(c)
+			continue
+		}
+
+		tag := text[i+1 : i+1+end]
+
+		if strings.HasPrefix(tag, "/") {
+			if len(stack) == 0 || !stack[len(stack)-1].closedBy(tag[1:]) {
+				segment.WriteByte(c)
+				continue
+			}
+
+			flush()
+			stack = stack[:len(stack)-1]
+			i += end + 1
+			continue
+		}
+
+		style, ok := f.parseTag(tag)
+		if !ok {
+			segment.WriteByte(c)
+			continue
+		}
+
+		flush()
+		if len(stack) > 0 {
+			style = style.Inherit(stack[len(stack)-1].style)
+		}
+		stack = append(stack, openTag{name: tag, style: style})
+		i += end + 1
+	}
+
+	flush()
+
+	return out.String()
+}
+
+// Strip removes all tags from text.
+func (f *Formatter) Strip(text string) string {
+	return f.Format(color.NoColor, text)
+}
+
+// Escape prevents tags in text from being formatted.
+func Escape(text string) string {
+	return strings.ReplaceAll(text, "<", "\\<")
+}
+
+// Sprintf formats like fmt.Sprintf and escapes the tags in the arguments,
+// the format itself may contain tags.
+func Sprintf(format string, args ...any) string {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		if _, ok := arg.(int); ok {
+			// ints contain no tags and may be a width or precision
+			escaped[i] = arg
+			continue
+		}
+
+		escaped[i] = escapedArg{arg}
+	}
+
+	return fmt.Sprintf(format, escaped...)
+}
+
+// escapedArg formats its value with the verb and flags it is printed with
+// and escapes the result.
+type escapedArg struct {
+	value any
+}
+
+func (a escapedArg) Format(s fmt.State, verb rune) {
+	io.WriteString(s, Escape(fmt.Sprintf(fmt.FormatString(s, verb), a.value)))
+}
+
+type openTag struct {
+	name  string
+	style color.Style
+}
+
+// closedBy reports whether </name> closes the tag, </> closes any tag.
+func (t openTag) closedBy(name string) bool {
+	return name == "" || strings.EqualFold(name, t.name)
+}
+
+func (f *Formatter) parseTag(tag string) (color.Style, bool) {
+	if style, ok := f.Style(tag); ok {
+		return style, true
+	}
+
+	if !strings.Contains(tag, "=") {
+		return color.Style{}, false
+	}
+
+	style := color.NewStyle()
+	for _, attribute := range strings.FieldsFunc(tag, func(r rune) bool { return r == ';' || r == ' ' }) {
+		key, value, found := strings.Cut(attribute, "=")
+		if !found {
+			return color.Style{}, false
+		}
+
+		switch strings.ToLower(key) {
+		case "fg":
+			c, err := color.Parse(value)
+			if err != nil {
+				return color.Style{}, false
+			}
+			style = style.Foreground(c)
+		case "bg":
+			c, err := color.Parse(value)
+			if err != nil {
+				return color.Style{}, false
+			}
+			style = style.Background(c)
+		case "href":
+			style = style.Link(value)
+		case "options":
+			for _, option := range strings.Split(value, ",") {
+				switch strings.ToLower(strings.TrimSpace(option)) {
+				case "bold":
+					style = style.Bold()
+				case "dim":
+					style = style.Dim()
+				case "italic":
+					style = style.Italic()
+				case "underscore", "underline":
+					style = style.Underline()
+				case "strikethrough":
+					style = style.Strikethrough()
+				default:
+					return color.Style{}, false
+				}
+			}
+		default:
+			return color.Style{}, false
+		}
+	}
+
+	return style, true
+}
+
+func named(name string) color.Color {
+	c, _ := color.Named(name)
+	return c
+}
@@ -0,0 +1,57 @@
+package markup
+
+import (
+	"testing"
+
+	"github.com/evolidev/console/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	f := New()
+	f.SetStyle("brand", color.NewStyle().Foreground(color.Code(169)))
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"Named style", "<info>Deployed</info>", "\u001b[32mDeployed\u001b[0m"},
+		{"Registered style", "<brand>evoli</brand>", "\u001b[38;2;215;95;175mevoli\u001b[0m"},
+		{"Inline style", "<fg=#ff0 options=bold>v1.2</>", "\u001b[1;38;2;255;255;0mv1.2\u001b[0m"},
+		{"Inline style with semicolons", "<fg=red;bg=white>x</>", "\u001b[31;47mx\u001b[0m"},
+		{"Nested styles", "<info>a <options=underscore>b</> c</info>", "\u001b[32ma \u001b[0m\u001b[4;32mb\u001b[0m\u001b[32m c\u001b[0m"},
+		{"Hyperlink", "<href=https://evoli.dev>docs</>", "\u001b]8;;https://evoli.dev\u001b\\docs\u001b]8;;\u001b\\"},
+		{"Unknown tags are kept", "<nil> <div>", "<nil> <div>"},
+		{"Invalid inline style is kept", "<fg=nope>x</>", "<fg=nope>x</>"},
+		{"Closing tag without opening tag", "a</> b", "a</> b"},
+		{"Closing tag of another style", "<info>a</comment> b</info>", "\u001b[32ma</comment> b\u001b[0m"},
+		{"Closing tag of an outer style", "<info>a <comment>b</info></>", "\u001b[32ma \u001b[0m\u001b[33mb</info>\u001b[0m"},
+		{"Closing inline style by name", "<fg=red>x</fg=red>y", "\u001b[31mx\u001b[0my"},
+		{"Escaped tag", "\\<info>literal", "<info>literal"},
+		{"Unclosed tag", "<info>open", "\u001b[32mopen\u001b[0m"},
+		{"Comparison", "1 < 2 > 0", "1 < 2 > 0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, f.Format(color.TrueColor, test.text))
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	f := New()
+
+	assert.Equal(t, "Deployed v1.2 <nil>", f.Strip("<info>Deployed</info> <fg=#ff0 options=bold>v1.2</> <nil>"))
+	assert.Equal(t, "<info>", f.Strip(Escape("<info>")))
+}
+
+func TestSprintf(t *testing.T) {
+	f := New()
+
+	text := Sprintf("<info>%s</info> %5.1f %*d %v", "<comment>a</comment> \\<b>", 1.25, 3, 7, []string{"<x>"})
+
+	assert.Equal(t, "<info>\\<comment>a\\</comment> \\\\<b></info>   1.2   7 [\\<x>]", text)
+	assert.Equal(t, "<comment>a</comment> \\<b>   1.2   7 [<x>]", f.Strip(text))
+}
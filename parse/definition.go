@@ -0,0 +1,494 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// Definition is the compiled form of a command definition like
+//
+//	mail:send {user : The ID of the user} {files?*} {--Q|queue=default : The queue}
+//
+// Arguments are written as {name}, optional ones as {name?} or {name=default}
+// and variadic ones as {name*}. Options start with two dashes, may have
+// aliases separated by |, take a value when followed by = and accept
+// multiple values with =*. Everything after a colon is the description.
+type Definition struct {
+	Source    string
+	Name      string
+	Arguments []*ArgumentSpec
+	Options   []*OptionSpec
+
+	options map[string]*OptionSpec
+}
+
+type ArgumentSpec struct {
+	Name        string
+	Description string
+	Optional    bool
+	Variadic    bool
+	Default     string
+	HasDefault  bool
+	Pos         int
+}
+
+type OptionSpec struct {
+	Name        string
+	Aliases     []string
+	Description string
+	TakesValue  bool
+	Array       bool
+	Default     string
+	HasDefault  bool
+	Pos         int
+}
+
+// Names returns the name and all aliases of the option.
+func (o *OptionSpec) Names() []string {
+	return append([]string{o.Name}, o.Aliases...)
+}
+
+func (d *Definition) Argument(name string) *ArgumentSpec {
+	for _, argument := range d.Arguments {
+		if argument.Name == name {
+			return argument
+		}
+	}
+
+	return nil
+}
+
+// Option returns the option with the given name or alias.
+func (d *Definition) Option(name string) *OptionSpec {
+	if d.options != nil {
+		return d.options[name]
+	}
+
+	for _, option := range d.Options {
+		if option.Name == name {
+			return option
+		}
+		for _, alias := range option.Aliases {
+			if alias == name {
+				return option
+			}
+		}
+	}
+
+	return nil
+}
+
+// SyntaxError describes an invalid definition. Column is the 1-based
+// position of the error in the definition.
+type SyntaxError struct {
+	Definition string
+	Column     int
+	Message    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid definition %q at column %d: %s", e.Definition, e.Column, e.Message)
+}
+
+// Compile parses and validates a definition.
+func Compile(definition string) (*Definition, error) {
+	p := &definitionParser{source: definition}
+
+	def, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// MustCompile is like Compile but panics when the definition is invalid.
+func MustCompile(definition string) *Definition {
+	def, err := Compile(definition)
+	if err != nil {
+		panic(err)
+	}
+
+	return def
+}
+
+func (d *Definition) validate() error {
+	seen := make(map[string]bool)
+	optional := false
+
+	for i, argument := range d.Arguments {
+		if seen[argument.Name] {
+			return d.errorAt(argument.Pos, fmt.Sprintf("duplicate argument %q", argument.Name))
+		}
+		seen[argument.Name] = true
+
+		if argument.Variadic && i != len(d.Arguments)-1 {
+			return d.errorAt(argument.Pos, fmt.Sprintf("variadic argument %q must be the last argument", argument.Name))
+		}
+
+		if optional && !argument.Optional {
+			return d.errorAt(argument.Pos, fmt.Sprintf("required argument %q after an optional argument", argument.Name))
+		}
+		optional = optional || argument.Optional
+	}
+
+	d.options = make(map[string]*OptionSpec)
+	for _, option := range d.Options {
+		for _, name := range option.Names() {
+			if _, ok := d.options[name]; ok {
+				return d.errorAt(option.Pos, fmt.Sprintf("duplicate option %q", name))
+			}
+			d.options[name] = option
+		}
+	}
+
+	return nil
+}
+
+func (d *Definition) errorAt(pos int, message string) error {
+	return &SyntaxError{Definition: d.Source, Column: pos + 1, Message: message}
+}
+
+type definitionParser struct {
+	source string
+	pos    int
+}
+
+func (p *definitionParser) parse() (*Definition, error) {
+	def := &Definition{Source: p.source}
+
+	p.skipSpaces()
+	start := p.pos
+	for !p.eof() && !isSpace(p.peek()) {
+		if p.peek() == '{' || p.peek() == '}' {
+			return nil, p.errorf("unexpected %q in command name", p.peek())
+		}
+		p.pos++
+	}
+
+	def.Name = p.source[start:p.pos]
+	if def.Name == "" {
+		return nil, p.errorf("missing command name")
+	}
+
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return def, nil
+		}
+
+		if p.peek() != '{' {
+			return nil, p.errorf("expected '{' but found %q", p.peek())
+		}
+
+		if err := p.parseElement(def); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *definitionParser) parseElement(def *Definition) error {
+	open := p.pos
+	p.pos++
+	p.skipSpaces()
+
+	if strings.HasPrefix(p.source[p.pos:], "--") {
+		option, err := p.parseOption(open)
+		if err != nil {
+			return err
+		}
+		def.Options = append(def.Options, option)
+	} else {
+		argument, err := p.parseArgument(open)
+		if err != nil {
+			return err
+		}
+		def.Arguments = append(def.Arguments, argument)
+	}
+
+	return nil
+}
+
+func (p *definitionParser) parseArgument(open int) (*ArgumentSpec, error) {
+	argument := &ArgumentSpec{Pos: open}
+
+	name, err := p.parseIdentifier("argument name")
+	if err != nil {
+		return nil, err
+	}
+	argument.Name = name
+
+	for p.peek() == '?' || p.peek() == '*' {
+		if p.peek() == '?' {
+			argument.Optional = true
+		} else {
+			argument.Variadic = true
+		}
+		p.pos++
+	}
+
+	p.skipSpaces()
+	if p.peek() == '=' {
+		if argument.Variadic {
+			return nil, p.errorf("variadic argument %q cannot have a default value", name)
+		}
+
+		p.pos++
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		argument.Default = value
+		argument.HasDefault = true
+		argument.Optional = true
+	}
+
+	description, err := p.parseEnd()
+	if err != nil {
+		return nil, err
+	}
+	argument.Description = description
+
+	return argument, nil
+}
+
+func (p *definitionParser) parseOption(open int) (*OptionSpec, error) {
+	option := &OptionSpec{Pos: open}
+	p.pos += 2
+
+	name, err := p.parseIdentifier("option name")
+	if err != nil {
+		return nil, err
+	}
+	option.Name = name
+
+	for p.peek() == '|' {
+		p.pos++
+		alias, err := p.parseIdentifier("option alias")
+		if err != nil {
+			return nil, err
+		}
+		option.Aliases = append(option.Aliases, alias)
+	}
+
+	if p.peek() == '?' {
+		p.pos++
+	}
+
+	p.skipSpaces()
+	if p.peek() == '=' {
+		p.pos++
+		option.TakesValue = true
+
+		if p.peek() == '*' {
+			p.pos++
+			option.Array = true
+		} else {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+
+			option.Default = value
+			option.HasDefault = value != ""
+		}
+	}
+
+	description, err := p.parseEnd()
+	if err != nil {
+		return nil, err
+	}
+	option.Description = description
+
+	return option, nil
+}
+
+// parseValue parses a default value which is either quoted or ends at the
+// next space or closing brace.
+func (p *definitionParser) parseValue() (string, error) {
+	start := p.pos
+	p.skipSpaces()
+
+	// {--queue= : The queue} has no default but a description
+	if p.pos > start && p.peek() == ':' {
+		return "", nil
+	}
+
+	if p.peek() == '"' || p.peek() == '\'' {
+		quote := p.peek()
+		start = p.pos
+		p.pos++
+
+		end := strings.IndexByte(p.source[p.pos:], quote)
+		if end < 0 {
+			p.pos = start
+			return "", p.errorf("unclosed quote")
+		}
+
+		value := p.source[p.pos : p.pos+end]
+		p.pos += end + 1
+		return value, nil
+	}
+
+	start = p.pos
+	for !p.eof() && !isSpace(p.peek()) && p.peek() != '}' && p.peek() != '{' {
+		p.pos++
+	}
+
+	return p.source[start:p.pos], nil
+}
+
+// parseEnd parses an optional description and the closing brace.
+func (p *definitionParser) parseEnd() (string, error) {
+	p.skipSpaces()
+
+	description := ""
+	if p.peek() == ':' {
+		p.pos++
+		start := p.pos
+		for !p.eof() && p.peek() != '}' {
+			if p.peek() == '{' {
+				return "", p.errorf("unexpected '{' in description")
+			}
+			p.pos++
+		}
+		description = strings.TrimSpace(p.source[start:p.pos])
+	}
+
+	if p.eof() {
+		return "", p.errorf("missing closing '}'")
+	}
+
+	if p.peek() != '}' {
+		return "", p.errorf("unexpected %q", p.peek())
+	}
+	p.pos++
+
+	return description, nil
+}
+
+func (p *definitionParser) parseIdentifier(what string) (string, error) {
+	start := p.pos
+	for !p.eof() && isIdentifier(p.peek()) {
+		p.pos++
+	}
+
+	if start == p.pos {
+		if p.eof() {
+			return "", p.errorf("missing %s", what)
+		}
+
+		return "", p.errorf("expected %s but found %q", what, p.peek())
+	}
+
+	return p.source[start:p.pos], nil
+}
+
+func (p *definitionParser) skipSpaces() {
+	for !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *definitionParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.source[p.pos]
+}
+
+func (p *definitionParser) eof() bool {
+	return p.pos >= len(p.source)
+}
+
+func (p *definitionParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Definition: p.source, Column: p.pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isIdentifier(c byte) bool {
+	return c == '_' || c == '-' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+var ErrInvalidInput = errors.New("invalid input")
+
+// Bind validates structured arguments and options against the definition
+// and returns them as a parsed command, without going through the command
+// line. Options may be given by name or alias.
+func (d *Definition) Bind(args map[string]any, opts map[string]any) (*ParsedCommand, error) {
+	arguments, options := d.defaults()
+
+	for name, value := range args {
+		argument := d.Argument(name)
+		if argument == nil {
+			return nil, fmt.Errorf("%w: unknown argument %q for %s", ErrInvalidInput, name, d.Name)
+		}
+
+		if argument.Variadic {
+			values, err := cast.ToStringSliceE(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: argument %q expects a list: %s", ErrInvalidInput, name, err)
+			}
+			value = values
+		}
+
+		arguments[name] = value
+	}
+
+	for _, argument := range d.Arguments {
+		if _, ok := args[argument.Name]; !ok && !argument.Optional {
+			return nil, fmt.Errorf("%w: missing argument %q for %s", ErrInvalidInput, argument.Name, d.Name)
+		}
+	}
+
+	for name, value := range opts {
+		option := d.Option(name)
+		if option == nil {
+			return nil, fmt.Errorf("%w: unknown option %q for %s", ErrInvalidInput, name, d.Name)
+		}
+
+		switch {
+		case option.Array:
+			values, err := cast.ToStringSliceE(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: option %q expects a list: %s", ErrInvalidInput, name, err)
+			}
+			value = values
+		case !option.TakesValue:
+			flag, err := cast.ToBoolE(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: option %q is a flag: %s", ErrInvalidInput, name, err)
+			}
+			value = flag
+		}
+
+		options[option.Name] = value
+		for _, alias := range option.Aliases {
+			options[alias] = value
+		}
+	}
+
+	prefix, subCommand, _ := strings.Cut(d.Name, ":")
+
+	return &ParsedCommand{
+		Arguments:  arguments,
+		Options:    options,
+		Command:    d.Name,
+		Name:       d.Name,
+		SubCommand: subCommand,
+		Prefix:     prefix,
+	}, nil
+}
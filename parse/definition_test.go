@@ -0,0 +1,108 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	def, err := Compile("mail:send {user : The ID of the user} {files?*} {--Q|queue = default : The queue} {--tag=*} {--time=10:00} {--dry-run}")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "mail:send", def.Name)
+	assert.Len(t, def.Arguments, 2)
+	assert.Len(t, def.Options, 4)
+
+	assert.Equal(t, &ArgumentSpec{Name: "user", Description: "The ID of the user", Pos: 10}, def.Arguments[0])
+	assert.True(t, def.Argument("files").Variadic)
+	assert.True(t, def.Argument("files").Optional)
+
+	queue := def.Option("queue")
+	assert.Equal(t, "Q", queue.Name)
+	assert.Equal(t, []string{"queue"}, queue.Aliases)
+	assert.Equal(t, "default", queue.Default)
+	assert.Equal(t, "The queue", queue.Description)
+	assert.True(t, queue.TakesValue)
+
+	assert.True(t, def.Option("tag").Array)
+	assert.Equal(t, "10:00", def.Option("time").Default)
+	assert.False(t, def.Option("dry-run").TakesValue)
+}
+
+func TestCompileDefaults(t *testing.T) {
+	def := MustCompile(`greet {name="Lisa Simpson"} {--queue= : The queue}`)
+
+	assert.Equal(t, "Lisa Simpson", def.Argument("name").Default)
+	assert.True(t, def.Argument("name").Optional)
+	assert.Equal(t, "", def.Option("queue").Default)
+	assert.Equal(t, "The queue", def.Option("queue").Description)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		definition string
+		column     int
+		message    string
+	}{
+		{"", 1, "missing command name"},
+		{"mail:send {user", 16, "missing closing '}'"},
+		{"mail:send user}", 11, "expected '{' but found 'u'"},
+		{"mail:send {}", 12, "expected argument name but found '}'"},
+		{"mail:send {--}", 14, "expected option name but found '}'"},
+		{"mail:send {user!}", 16, "unexpected '!'"},
+		{"mail:send {--name = foo bar}", 25, "unexpected 'b'"},
+		{`mail:send {--name="foo}`, 19, "unclosed quote"},
+		{"mail:send {user} {user}", 18, `duplicate argument "user"`},
+		{"mail:send {--Q|queue} {--queue}", 23, `duplicate option "queue"`},
+		{"mail:send {user?} {id}", 19, `required argument "id" after an optional argument`},
+		{"mail:send {files*} {id}", 11, `variadic argument "files" must be the last argument`},
+		{"mail:send {files*=a}", 18, `variadic argument "files" cannot have a default value`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.definition, func(t *testing.T) {
+			_, err := Compile(test.definition)
+
+			syntaxErr, ok := err.(*SyntaxError)
+			if assert.True(t, ok, "expected a syntax error but got %v", err) {
+				assert.Equal(t, test.column, syntaxErr.Column)
+				assert.Equal(t, test.message, syntaxErr.Message)
+			}
+		})
+	}
+}
+
+func TestParseVariadic(t *testing.T) {
+	cmd := Parse("copy {target} {files*} {--exclude=*}", "copy dist --exclude=a a.go --exclude=b b.go")
+
+	assert.Equal(t, "dist", cmd.GetArgument("target").String())
+	assert.Equal(t, []string{"a.go", "b.go"}, cmd.GetArgument("files").Strings())
+	assert.Equal(t, []string{"a", "b"}, cmd.GetOption("exclude").Strings())
+}
+
+const (
+	benchmarkDefinition = "mail:send {user} {files?*} {--Q|queue=default} {--dry-run}"
+	benchmarkCommand    = "mail:send 42 a.go b.go --queue=high -Q --dry-run"
+)
+
+// BenchmarkParse compiles the definition on every call. It only uses Parse,
+// so it also runs against the regex based parser from before definitions
+// were compiled and is the benchmark to compare the two with.
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Parse(benchmarkDefinition, benchmarkCommand)
+	}
+}
+
+func BenchmarkDefinitionParse(b *testing.B) {
+	def := MustCompile(benchmarkDefinition)
+	args := []string{"mail:send", "42", "a.go", "b.go", "--queue=high", "-Q", "--dry-run"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		def.Parse(args)
+	}
+}
@@ -3,12 +3,9 @@ package parse
 import (
 	"fmt"
 	"github.com/spf13/cast"
-	"regexp"
 	"strings"
 )
 
-var parseRegex = `[\-\\\/]{0,2}?((\w+)(?:[=:](\"[^\"]+\"|[^\s\"]+))?|((\/|\.\.?|)[^-\s][^"\s]*))(?:\s+|$)`
-
 type ParsedCommand struct {
 	Arguments  map[string]any
 	Options    map[string]any
@@ -35,6 +32,11 @@ func (o *Value) String() string {
 	return cast.ToString(o.Value)
 }
 
+// Strings returns the values of variadic arguments and array options.
+func (o *Value) Strings() []string {
+	return cast.ToStringSlice(o.Value)
+}
+
 func (p *ParsedCommand) HasOption(name string) bool {
 	if cmd, ok := p.Options[name]; ok {
 		return cmd != nil
@@ -98,102 +100,192 @@ func (p *ParsedCommand) GetArgumentWithDefault(name string, defaultValue any) *V
 	return &Value{Value: argumentValue}
 }
 
+// Parse compiles the definition and parses the command line. Commands which
+// are executed more than once should compile their definition once and use
+// Definition.Parse instead.
 func Parse(definition string, command string) *ParsedCommand {
-	arguments := make(map[string]any)
-	options := make(map[string]any)
+	def, err := Compile(definition)
+	if err != nil {
+		// definitions are validated when commands are registered, a broken
+		// one defines neither arguments nor options
+		def = &Definition{Source: definition}
+	}
 
-	var argumentsMap []string
+	parsed := def.Parse(splitCommand(command))
+	parsed.Command = command
 
-	// parse definition
-	argumentsMap = parseDefinition(definition, options, arguments, argumentsMap)
+	return parsed
+}
 
-	items := parseCommand(command, options, argumentsMap, arguments)
+// Parse parses the arguments of a command, args[0] being its name.
+func (d *Definition) Parse(args []string) *ParsedCommand {
+	arguments, options := d.defaults()
 
-	name := items[0]
-	// split name into prefix and subcommand
-	nameParts := strings.Split(name, ":")
-	prefix := nameParts[0]
-	subCommand := ""
-	if len(nameParts) > 1 {
-		subCommand = nameParts[1]
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	position := 0
+	endOfOptions := false
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		if !endOfOptions && arg == "--" {
+			endOfOptions = true
+			continue
+		}
+
+		if endOfOptions || !isOption(arg) {
+			d.setArgument(arguments, position, arg)
+			position++
+			continue
+		}
+
+		prefix := "-"
+		if strings.HasPrefix(arg, "--") {
+			prefix = "--"
+		}
+
+		optionName, optionValue := ExtractField(arg, prefix)
+
+		// options which take a value may get it from the next argument
+		option := d.Option(optionName)
+		if option != nil && option.TakesValue && !strings.Contains(arg, "=") && i+1 < len(args) && !isOption(args[i+1]) {
+			optionValue = args[i+1]
+			i++
+		}
+
+		setOption(option, options, optionName, optionValue)
 	}
 
+	// split name into prefix and subcommand
+	prefix, subCommand, _ := strings.Cut(name, ":")
+
 	return &ParsedCommand{
 		Arguments:  arguments,
 		Options:    options,
-		Command:    command,
+		Command:    strings.Join(args, " "),
 		Name:       name,
 		SubCommand: subCommand,
 		Prefix:     prefix,
 	}
 }
 
-func parseDefinition(definition string, options map[string]any, arguments map[string]any, argumentsMap []string) []string {
-	definitionItems := strings.Split(definition, " ")
-	for i := range definitionItems {
-		definitionItems[i] = strings.TrimSpace(definitionItems[i])
+// defaults returns the arguments and options with their default values.
+func (d *Definition) defaults() (map[string]any, map[string]any) {
+	arguments := make(map[string]any, len(d.Arguments))
+	for _, argument := range d.Arguments {
+		if argument.Variadic {
+			arguments[argument.Name] = []string{}
+			continue
+		}
+
+		arguments[argument.Name] = argument.Default
+	}
 
-		// parse definition item
-		definitionItem := definitionItems[i]
-		// remove curly bracket at the beginning and end of definition item
-		definitionItem = strings.Trim(definitionItem, "{}?")
+	options := make(map[string]any, len(d.Options))
+	for _, option := range d.Options {
+		var value any = option.Default
+		if option.Array {
+			value = []string{}
+		}
 
-		// split definition item into name and Value
-		definitionItemParts := strings.Split(definitionItem, "=")
-		definitionItemName := definitionItemParts[0]
-		definitionItemValue := ""
-		if len(definitionItemParts) > 1 {
-			definitionItemValue = definitionItemParts[1]
+		options[option.Name] = value
+		for _, alias := range option.Aliases {
+			options[alias] = value
 		}
+	}
 
-		if strings.HasPrefix(definitionItemName, "--") {
-			optionName := strings.TrimPrefix(definitionItemName, "--")
+	return arguments, options
+}
 
-			optionNameParts := strings.Split(optionName, "|")
-			for index := range optionNameParts {
-				optionName = strings.TrimSpace(optionNameParts[index])
-				options[optionName] = definitionItemValue
-			}
-		} else {
-			arguments[definitionItemName] = definitionItemValue
-			argumentsMap = append(argumentsMap, definitionItemName)
-		}
+func setOption(option *OptionSpec, options map[string]any, name string, value any) {
+	if option == nil {
+		options[name] = value
+		return
+	}
+
+	if option.Array {
+		values, _ := options[option.Name].([]string)
+		value = append(values, cast.ToString(value))
+	}
+
+	options[option.Name] = value
+	for _, alias := range option.Aliases {
+		options[alias] = value
 	}
-	return argumentsMap
 }
 
-func parseCommand(command string, options map[string]any, argumentsMap []string, arguments map[string]any) []string {
-	// extract all arguments and options
-	r, _ := regexp.Compile(parseRegex)
-	items := r.FindAllString(command, -1)
-	for i := range items {
-		items[i] = strings.TrimSpace(items[i])
+func (d *Definition) setArgument(arguments map[string]any, position int, value string) {
+	if len(d.Arguments) == 0 {
+		return
+	}
+
+	if position >= len(d.Arguments)-1 {
+		last := d.Arguments[len(d.Arguments)-1]
+		if last.Variadic {
+			values, _ := arguments[last.Name].([]string)
+			arguments[last.Name] = append(values, value)
+			return
+		}
+	}
+
+	if position < len(d.Arguments) {
+		arguments[d.Arguments[position].Name] = value
 	}
+}
 
-	for index, item := range items {
-		if strings.HasPrefix(item, "--") {
-			optionName, optionValue := ExtractField(item, "--")
-			options[optionName] = optionValue
-		} else if strings.HasPrefix(item, "-") {
-			optionName, optionValue := ExtractField(item, "-")
-			options[optionName] = optionValue
-		} else {
-			if index > 0 && index < len(argumentsMap) {
-				arguments[argumentsMap[index]] = item
+func isOption(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-'
+}
+
+// splitCommand splits a command line into arguments, respecting quotes.
+func splitCommand(command string) []string {
+	var args []string
+	var current strings.Builder
+	var quote byte
+	inArgument := false
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArgument = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArgument {
+				args = append(args, current.String())
+				current.Reset()
+				inArgument = false
 			}
+		default:
+			current.WriteByte(c)
+			inArgument = true
 		}
 	}
-	return items
+
+	if inArgument {
+		args = append(args, current.String())
+	}
+
+	return args
 }
 
 func ExtractField(item string, prefix string) (string, any) {
 	option := strings.TrimPrefix(item, prefix)
 	// extract option name and Value
-	parts := strings.Split(option, "=")
-	optionName := parts[0]
+	optionName, value, _ := strings.Cut(option, "=")
 	var optionValue any
-	if len(parts) > 1 && parts[1] != "" {
-		optionValue = parts[1]
+	if value != "" {
+		optionValue = value
 	} else {
 		optionValue = true
 	}
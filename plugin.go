@@ -0,0 +1,230 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/evolidev/console/parse"
+)
+
+// PluginDescribeTimeout limits how long a plugin may take to answer the
+// --describe handshake.
+var PluginDescribeTimeout = 2 * time.Second
+
+// PluginDescription is printed as JSON by a plugin when it is called with
+// --describe, e.g. {"description": "Deploy the application"}.
+type PluginDescription struct {
+	Description string `json:"description"`
+}
+
+// pluginOrigin is the origin of the commands added by LoadPlugins.
+const pluginOrigin = "a plugin"
+
+type plugin struct {
+	name string
+	path string
+}
+
+// LoadPlugins registers executables named <app>-<command> as commands, like
+// git and kubectl do. The given directories are searched before the PATH
+// and registered commands always take precedence over plugins. Arguments,
+// environment, input, output and the exit code are forwarded. Plugins are
+// only asked for their description when the commands are listed.
+func (c *Console) LoadPlugins(app string, dirs ...string) {
+	for _, p := range findPlugins(app, append(dirs, filepath.SplitList(os.Getenv("PATH"))...)) {
+		if _, ok := c.Commands[p.name]; ok {
+			continue
+		}
+
+		// a file name like "app-foo{" can't be a command name
+		definition := p.name + " {args?*}"
+		if def, err := parse.Compile(definition); err != nil || def.Name != p.name {
+			c.Logger().Warn("Skipping plugin with an invalid command name", "path", p.path, "name", p.name)
+			continue
+		}
+
+		command := &Command{Definition: definition, Handler: p.run}
+		c.Add(command)
+
+		if c.origins == nil {
+			c.origins = make(map[string]string)
+		}
+		c.origins[p.name] = pluginOrigin
+
+		c.plugins = append(c.plugins, pluginCommand{plugin: p, command: command})
+	}
+}
+
+// pluginCommand is a command added by LoadPlugins, until it is described.
+type pluginCommand struct {
+	plugin
+	command *Command
+}
+
+// describePlugins asks the loaded plugins for their descriptions, in
+// parallel and once.
+func (c *Console) describePlugins() {
+	var wg sync.WaitGroup
+	for _, p := range c.plugins {
+		if c.Commands[p.name] != p.command {
+			// replaced by a registered command
+			continue
+		}
+
+		wg.Add(1)
+		go func(p pluginCommand) {
+			defer wg.Done()
+			p.command.Description = p.describe()
+		}(p)
+	}
+	wg.Wait()
+
+	c.plugins = nil
+}
+
+func findPlugins(app string, dirs []string) []plugin {
+	prefix := app + "-"
+	seen := make(map[string]bool)
+
+	var plugins []plugin
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, prefix) || entry.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(dir, name)
+			if !isExecutable(path) {
+				continue
+			}
+
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+
+			command := strings.TrimPrefix(name, prefix)
+			if command == "" || seen[command] {
+				continue
+			}
+			seen[command] = true
+
+			plugins = append(plugins, plugin{name: command, path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].name < plugins[j].name
+	})
+
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+
+	return info.Mode()&0111 != 0
+}
+
+func (p plugin) describe() string {
+	ctx, cancel := context.WithTimeout(context.Background(), PluginDescribeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, p.path, "--describe").Output()
+	if err != nil {
+		return ""
+	}
+
+	var description PluginDescription
+	if err := json.Unmarshal(out, &description); err != nil {
+		return ""
+	}
+
+	return description.Description
+}
+
+func (p plugin) run(ctx *Context) error {
+	// the arguments are forwarded as given, including the global options
+	// the console would take for itself
+	args := ctx.rawArgs
+	if args == nil && len(ctx.Args) > 0 {
+		args = ctx.Args[1:]
+	}
+
+	cmd := exec.CommandContext(ctx, p.path, args...)
+	cmd.Stdin = ctx.Input
+	cmd.Stdout = ctx.Output
+	cmd.Stderr = ctx.ErrOutput
+
+	cmd.Env = os.Environ()
+	for key, value := range ctx.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return Exit(exitCode(exitErr), nil)
+	}
+
+	return err
+}
+
+// exitCode returns the exit code of a plugin, 128 plus the number of the
+// signal when it was killed, like shells report it.
+func exitCode(err *exec.ExitError) int {
+	if code := err.ExitCode(); code != -1 {
+		return code
+	}
+
+	if status, ok := err.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+
+	return 1
+}
+
+// Complete returns the names of the commands, including plugins, which start
+// with prefix.
+func (c *Console) Complete(prefix string) []string {
+	// the commands of a failed boot are completed as far as they were added
+	_ = c.Boot()
+
+	var names []string
+	for name := range c.Commands {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
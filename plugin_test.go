@@ -0,0 +1,139 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/evolidev/console/parse"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePlugin(t *testing.T, dir string, name string, script string) {
+	err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755)
+	assert.Nil(t, err)
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", "")
+
+	writePlugin(t, dir, "app-hello", `
+if [ "$1" = "--describe" ]; then
+  : > "$0.described"
+  echo '{"description": "Say hello"}'
+  exit 0
+fi
+echo "hello $1 $STAGE"
+read name
+echo "bye $name" >&2
+exit 4
+`)
+	writePlugin(t, dir, "app-silent", "exit 0\n")
+	writePlugin(t, dir, "app-builtin", "echo plugin\n")
+	writePlugin(t, dir, "other-tool", "exit 0\n")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app-readme"), []byte("not executable"), 0644))
+
+	cli := New()
+	cli.DisableColors()
+	cli.AddCommand("builtin", "Built-in command", func(cmd *parse.ParsedCommand) {})
+
+	cli.LoadPlugins("app", dir)
+
+	assert.Equal(t, []string{"builtin", "hello", "silent"}, cli.Complete(""))
+	assert.Equal(t, []string{"hello"}, cli.Complete("he"))
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	cli.Output = out
+	cli.ErrOutput = errOut
+	cli.Input = strings.NewReader("Lisa\n")
+	cli.Env = map[string]string{"STAGE": "staging"}
+
+	err := cli.Execute(context.Background(), []string{"hello", "world"})
+
+	assert.Equal(t, 4, ExitCode(err))
+	assert.Equal(t, "hello world staging\n", out.String())
+	assert.Equal(t, "bye Lisa\n", errOut.String())
+
+	// plugins are only described for the list of commands
+	assert.NoFileExists(t, filepath.Join(dir, "app-hello.described"))
+
+	cli.Render()
+
+	assert.FileExists(t, filepath.Join(dir, "app-hello.described"))
+	assert.Contains(t, out.String(), "Say hello")
+	assert.Equal(t, "Say hello", cli.Commands["hello"].Description)
+	assert.Equal(t, "", cli.Commands["silent"].Description)
+	assert.Equal(t, "Built-in command", cli.Commands["builtin"].Description)
+}
+
+func TestPluginKilledBySignal(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", "")
+
+	writePlugin(t, dir, "app-crash", "kill -TERM $$\n")
+
+	cli := New()
+	cli.ErrOutput = &bytes.Buffer{}
+	cli.LoadPlugins("app", dir)
+
+	err := cli.Execute(context.Background(), []string{"crash"})
+	assert.Equal(t, 128+int(syscall.SIGTERM), ExitCode(err))
+}
+
+func TestPluginArguments(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", "")
+
+	writePlugin(t, dir, "app-args", `printf '%s|' "$@"`)
+	writePlugin(t, dir, "app-broken{", "exit 0\n")
+	writePlugin(t, dir, "app-with space", "exit 0\n")
+
+	cli := New()
+	cli.DisableColors()
+	cli.ErrOutput = &bytes.Buffer{}
+
+	assert.NotPanics(t, func() { cli.LoadPlugins("app", dir) })
+	assert.Equal(t, []string{"args"}, cli.Complete(""))
+
+	out := &bytes.Buffer{}
+	cli.Output = out
+
+	err := cli.Execute(context.Background(), []string{"args", "-v", "", "--quiet", "-test.run=x", "file"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "-v||--quiet|-test.run=x|file|", out.String())
+}
+
+func TestRegisteredCommandsTakePrecedenceOverPlugins(t *testing.T) {
+	resetRegistry(t)
+
+	dir := t.TempDir()
+	t.Setenv("PATH", "")
+
+	writePlugin(t, dir, "app-deploy", "echo plugin\n")
+	writePlugin(t, dir, "app-db:migrate", "echo plugin\n")
+
+	var setup []string
+	RegisterProvider(testProvider{name: "db", commands: []string{"db:migrate"}, setup: &setup})
+	Register(&Command{Definition: "deploy", Handler: func(ctx *Context) error {
+		ctx.Println("registry deploy")
+		return nil
+	}})
+
+	out := &bytes.Buffer{}
+	cli := New()
+	cli.DisableColors()
+	cli.Output = out
+
+	cli.LoadPlugins("app", dir)
+
+	assert.Nil(t, cli.Execute(context.Background(), []string{"deploy"}))
+	assert.Nil(t, cli.Execute(context.Background(), []string{"db:migrate"}))
+	assert.Equal(t, "registry deploy\ndb db:migrate\n", out.String())
+}
@@ -0,0 +1,439 @@
+package console
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evolidev/console/terminal"
+)
+
+const (
+	DefaultProgressFormat = "%current%/%max% [%bar%] %percent%% %elapsed%/%eta% %rate%/s %message%"
+
+	progressRedrawInterval = 50 * time.Millisecond
+	progressLineInterval   = time.Second
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+type progressWidget interface {
+	line() string
+}
+
+// progressArea keeps track of all running progress bars and spinners of a
+// console. On a terminal the widgets are redrawn in place below the regular
+// output, otherwise their state is printed line by line every few seconds.
+type progressArea struct {
+	console   *Console
+	mu        sync.Mutex
+	widgets   []progressWidget
+	printed   map[progressWidget]time.Time
+	drawn     int
+	lastDrawn time.Time
+}
+
+func (a *progressArea) tty() bool {
+	return terminal.IsTerminal(a.console.Output)
+}
+
+func (a *progressArea) add(w progressWidget) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.console.Quiet {
+		return
+	}
+
+	a.widgets = append(a.widgets, w)
+	if a.tty() {
+		a.clear()
+		a.draw()
+	}
+}
+
+func (a *progressArea) remove(w progressWidget) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.console.Quiet {
+		return
+	}
+
+	for i, widget := range a.widgets {
+		if widget == w {
+			a.widgets = append(a.widgets[:i], a.widgets[i+1:]...)
+			break
+		}
+	}
+
+	if !a.tty() {
+		a.printLine(w)
+		delete(a.printed, w)
+		return
+	}
+
+	// the final state of the widget stays on the screen above the
+	// widgets which are still running
+	a.clear()
+	a.write(w.line() + "\n")
+	a.draw()
+}
+
+func (a *progressArea) refresh(w progressWidget) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.console.Quiet {
+		return
+	}
+
+	if !a.tty() {
+		if time.Since(a.printed[w]) >= progressLineInterval {
+			a.printLine(w)
+		}
+		return
+	}
+
+	if time.Since(a.lastDrawn) < progressRedrawInterval {
+		return
+	}
+
+	a.clear()
+	a.draw()
+}
+
+// print writes regular output above the running widgets.
+func (a *progressArea) print(s string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.drawn == 0 {
+		a.write(s)
+		return
+	}
+
+	a.clear()
+	a.write(s)
+	a.draw()
+}
+
+func (a *progressArea) printLine(w progressWidget) {
+	if a.printed == nil {
+		a.printed = make(map[progressWidget]time.Time)
+	}
+
+	a.printed[w] = time.Now()
+	a.write(w.line() + "\n")
+}
+
+func (a *progressArea) clear() {
+	if a.drawn == 0 {
+		return
+	}
+
+	a.write(fmt.Sprintf("\r\u001b[%dA\u001b[J", a.drawn))
+	a.drawn = 0
+}
+
+func (a *progressArea) draw() {
+	var b strings.Builder
+	for _, w := range a.widgets {
+		b.WriteString("\u001b[2K")
+		b.WriteString(w.line())
+		b.WriteString("\n")
+	}
+
+	a.write(b.String())
+	a.drawn = len(a.widgets)
+	a.lastDrawn = time.Now()
+}
+
+func (a *progressArea) write(s string) {
+	_, err := io.WriteString(a.console.Output, s)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type ProgressBar struct {
+	console  *Console
+	mu       sync.Mutex
+	total    int
+	current  int
+	width    int
+	format   string
+	message  string
+	started  time.Time
+	finished bool
+}
+
+// ProgressBar creates and displays a progress bar for the given amount of
+// steps. A total of zero renders a bar with an unknown amount of steps.
+func (c *Console) ProgressBar(total int) *ProgressBar {
+	bar := &ProgressBar{
+		console: c,
+		total:   total,
+		width:   28,
+		format:  DefaultProgressFormat,
+		started: time.Now(),
+	}
+
+	c.area().add(bar)
+
+	return bar
+}
+
+func (p *ProgressBar) SetFormat(format string) *ProgressBar {
+	p.mu.Lock()
+	p.format = format
+	p.mu.Unlock()
+
+	return p
+}
+
+// SetWidth sets the number of characters of the bar, widths below one are
+// ignored.
+func (p *ProgressBar) SetWidth(width int) *ProgressBar {
+	if width < 1 {
+		return p
+	}
+
+	p.mu.Lock()
+	p.width = width
+	p.mu.Unlock()
+
+	return p
+}
+
+func (p *ProgressBar) SetMessage(message string) {
+	p.mu.Lock()
+	p.message = message
+	p.mu.Unlock()
+
+	p.console.area().refresh(p)
+}
+
+func (p *ProgressBar) Advance(steps int) {
+	p.update(func(current int) int { return current + steps })
+}
+
+func (p *ProgressBar) Set(current int) {
+	p.update(func(int) int { return current })
+}
+
+// update changes the current step under the lock, so concurrent calls don't
+// lose steps. The bar is drawn with the latest state afterwards.
+func (p *ProgressBar) update(next func(current int) int) {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+
+	current := next(p.current)
+	if current < 0 {
+		current = 0
+	}
+	if p.total > 0 && current > p.total {
+		current = p.total
+	}
+	p.current = current
+	p.mu.Unlock()
+
+	p.console.area().refresh(p)
+}
+
+func (p *ProgressBar) Current() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.current
+}
+
+// Finish completes the bar and leaves its final state in the output.
+func (p *ProgressBar) Finish() {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+
+	p.finished = true
+	if p.total > 0 {
+		p.current = p.total
+	}
+	p.mu.Unlock()
+
+	p.console.area().remove(p)
+}
+
+func (p *ProgressBar) line() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elapsed := time.Since(p.started)
+
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.current) / elapsed.Seconds()
+	}
+
+	percent := 0
+	eta := "?"
+	if p.total > 0 {
+		percent = p.current * 100 / p.total
+		if p.current >= p.total {
+			eta = formatDuration(0)
+		} else if rate > 0 {
+			eta = formatDuration(time.Duration(float64(p.total-p.current) / rate * float64(time.Second)))
+		}
+	}
+
+	max := "?"
+	if p.total > 0 {
+		max = fmt.Sprintf("%d", p.total)
+	}
+
+	replacer := strings.NewReplacer(
+		"%current%", fmt.Sprintf("%d", p.current),
+		"%max%", max,
+		"%bar%", p.bar(),
+		"%percent%", fmt.Sprintf("%3d", percent),
+		"%elapsed%", formatDuration(elapsed),
+		"%eta%", eta,
+		"%rate%", fmt.Sprintf("%.1f", rate),
+		"%message%", p.message,
+	)
+
+	return strings.TrimRight(replacer.Replace(p.format), " ")
+}
+
+func (p *ProgressBar) bar() string {
+	filled := 0
+	if p.total > 0 {
+		filled = p.width * p.current / p.total
+	}
+
+	done := strings.Repeat("=", filled)
+	if filled < p.width && (p.current > 0 || p.total == 0) {
+		done += ">"
+	}
+
+	empty := strings.Repeat("-", max(p.width-len(done), 0))
+
+	return p.console.Text(169, done) + p.console.Text(240, empty)
+}
+
+type Spinner struct {
+	console *Console
+	mu      sync.Mutex
+	message string
+	frame   int
+	status  string
+	started time.Time
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Spinner displays an animated indicator for a task without known steps
+// until one of Stop, Success or Fail is called.
+func (c *Console) Spinner(message string) *Spinner {
+	s := &Spinner{
+		console: c,
+		message: message,
+		started: time.Now(),
+		done:    make(chan struct{}),
+	}
+
+	c.area().add(s)
+
+	if !terminal.IsTerminal(c.Output) {
+		c.area().refresh(s)
+	} else if !c.Quiet {
+		go s.animate()
+	}
+
+	return s
+}
+
+func (s *Spinner) SetMessage(message string) {
+	s.mu.Lock()
+	s.message = message
+	s.mu.Unlock()
+
+	s.console.area().refresh(s)
+}
+
+func (s *Spinner) Stop() {
+	s.stop("", "")
+}
+
+func (s *Spinner) Success(message string) {
+	s.stop(s.console.Text(114, "✔"), message)
+}
+
+func (s *Spinner) Fail(message string) {
+	s.stop(s.console.Text(203, "✖"), message)
+}
+
+func (s *Spinner) stop(status string, message string) {
+	s.once.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		s.status = status
+		if message != "" {
+			s.message = message
+		}
+		s.mu.Unlock()
+
+		s.console.area().remove(s)
+	})
+}
+
+func (s *Spinner) animate() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.frame = (s.frame + 1) % len(spinnerFrames)
+			s.mu.Unlock()
+
+			s.console.area().refresh(s)
+		}
+	}
+}
+
+func (s *Spinner) line() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.status
+	if status == "" {
+		status = s.console.Text(169, spinnerFrames[s.frame])
+	}
+
+	return fmt.Sprintf("%s %s %s", status, s.message, s.console.Text(245, formatDuration(time.Since(s.started))))
+}
+
+// area returns the progress area of the console, which is created with the
+// console, so concurrent output never races on it.
+func (c *Console) area() *progressArea {
+	return c.progress
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return "0s"
+	}
+
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,152 @@
+package console
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressBar(t *testing.T) {
+	t.Run("Render the final state of a progress bar", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+
+		out := &bytes.Buffer{}
+		cli.SetOutput(out)
+
+		bar := cli.ProgressBar(4)
+		bar.SetFormat("%current%/%max% [%bar%] %percent%%").SetWidth(4)
+		bar.Advance(2)
+		bar.Finish()
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+
+		assert.Equal(t, "2/4 [==>-]  50%", lines[0])
+		assert.Equal(t, "4/4 [====] 100%", lines[len(lines)-1])
+	})
+
+	t.Run("Render a progress bar with a message", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+
+		out := &bytes.Buffer{}
+		cli.SetOutput(out)
+
+		bar := cli.ProgressBar(0)
+		bar.SetFormat("%current%/%max% %message%")
+		bar.SetMessage("uploading")
+		bar.Advance(3)
+		bar.Finish()
+
+		assert.Contains(t, out.String(), "3/? uploading")
+	})
+
+	t.Run("Output of Println is not swallowed by a running bar", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+
+		out := &bytes.Buffer{}
+		cli.SetOutput(out)
+
+		bar := cli.ProgressBar(2)
+		cli.Println("migrated users")
+		bar.Finish()
+
+		assert.Contains(t, out.String(), "migrated users\n")
+	})
+
+	t.Run("Concurrent advances are all counted", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+		cli.SetOutput(&bytes.Buffer{})
+
+		bar := cli.ProgressBar(100)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bar.Advance(1)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 100, bar.Current())
+		bar.Finish()
+	})
+
+	t.Run("Concurrent output on a new console", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+
+		out := &bytes.Buffer{}
+		cli.SetOutput(out)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cli.Println("done")
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 10, strings.Count(out.String(), "done\n"))
+	})
+
+	t.Run("A width below one is ignored", func(t *testing.T) {
+		cli := New()
+		cli.DisableColors()
+
+		out := &bytes.Buffer{}
+		cli.SetOutput(out)
+
+		bar := cli.ProgressBar(4)
+		bar.SetFormat("[%bar%]").SetWidth(4).SetWidth(0).SetWidth(-2)
+
+		assert.NotPanics(t, func() {
+			bar.Advance(1)
+			bar.Finish()
+		})
+		assert.Contains(t, out.String(), "[=>--]")
+		assert.Contains(t, out.String(), "[====]")
+	})
+
+	t.Run("Quiet consoles do not render progress", func(t *testing.T) {
+		cli := New()
+		cli.SetQuiet(true)
+
+		out := &bytes.Buffer{}
+		cli.SetOutput(out)
+
+		bar := cli.ProgressBar(2)
+		bar.Advance(1)
+		bar.Finish()
+
+		spinner := cli.Spinner("working")
+		spinner.Success("done")
+
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestSpinner(t *testing.T) {
+	cli := New()
+	cli.DisableColors()
+
+	out := &bytes.Buffer{}
+	cli.SetOutput(out)
+
+	spinner := cli.Spinner("Building")
+	spinner.Success("Built")
+	spinner.Fail("ignored")
+
+	assert.Contains(t, out.String(), "Building")
+	assert.Contains(t, out.String(), "✔ Built")
+	assert.NotContains(t, out.String(), "ignored")
+}
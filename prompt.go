@@ -0,0 +1,144 @@
+package console
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/evolidev/console/terminal"
+	"golang.org/x/term"
+)
+
+// Ask prints the question and returns the answer read from the input, or the
+// default value when the answer is empty.
+func (c *Console) Ask(question string, defaultValue string) string {
+	if defaultValue != "" {
+		c.Printf("<info>%s</info> [<comment>%s</comment>]: ", question, defaultValue)
+	} else {
+		c.Printf("<info>%s</info>: ", question)
+	}
+
+	answer, _ := c.readLine()
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return defaultValue
+	}
+
+	return answer
+}
+
+// Confirm asks a yes/no question.
+func (c *Console) Confirm(question string, defaultValue bool) bool {
+	hint := "y/N"
+	if defaultValue {
+		hint = "Y/n"
+	}
+
+	c.Printf("<info>%s</info> [<comment>%s</comment>]: ", question, hint)
+
+	answer, _ := c.readLine()
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+
+	return defaultValue
+}
+
+// Choice lets the user pick one of the choices by number or by value. It
+// keeps asking until a valid choice was given or the input is exhausted.
+func (c *Console) Choice(question string, choices []string, defaultIndex int) string {
+	for {
+		c.Printf("<info>%s</info>\n", question)
+		for i, choice := range choices {
+			c.Printf("  [<comment>%d</comment>] %s\n", i+1, choice)
+		}
+
+		if defaultIndex >= 0 && defaultIndex < len(choices) {
+			c.Printf("> [<comment>%s</comment>] ", choices[defaultIndex])
+		} else {
+			c.Print("> ")
+		}
+
+		answer, err := c.readLine()
+		answer = strings.TrimSpace(answer)
+
+		if answer == "" && defaultIndex >= 0 && defaultIndex < len(choices) {
+			return choices[defaultIndex]
+		}
+
+		if index, convErr := strconv.Atoi(answer); convErr == nil && index > 0 && index <= len(choices) {
+			return choices[index-1]
+		}
+
+		for _, choice := range choices {
+			if strings.EqualFold(choice, answer) {
+				return choice
+			}
+		}
+
+		if err != nil {
+			return ""
+		}
+
+		c.Printf("<error>Value \"%s\" is invalid</error>\n", answer)
+	}
+}
+
+// Secret asks for a value without echoing it when the input is a terminal.
+func (c *Console) Secret(question string) string {
+	c.Printf("<info>%s</info>: ", question)
+
+	if file, ok := c.Input.(*os.File); ok && terminal.IsTerminal(file) {
+		value, err := term.ReadPassword(int(file.Fd()))
+		c.Println()
+		if err != nil {
+			return ""
+		}
+
+		return strings.TrimSpace(string(value))
+	}
+
+	answer, _ := c.readLine()
+	return strings.TrimSpace(answer)
+}
+
+// readLine reads a single line from the input. It reads byte by byte so the
+// remaining input stays available for the next question.
+func (c *Console) readLine() (string, error) {
+	input := c.Input
+	if input == nil {
+		input = os.Stdin
+	}
+
+	// the answer is not echoed when the input is not a terminal
+	if file, ok := input.(*os.File); !ok || !terminal.IsTerminal(file) {
+		defer c.Println()
+	}
+
+	var line []byte
+	buffer := make([]byte, 1)
+	for {
+		n, err := input.Read(buffer)
+		if n > 0 {
+			if buffer[0] == '\n' {
+				break
+			}
+			line = append(line, buffer[0])
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(line) > 0 {
+				break
+			}
+
+			return string(line), err
+		}
+	}
+
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
@@ -0,0 +1,158 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrCommandExists = errors.New("command is already registered")
+
+// CommandProvider bundles the commands of a feature package. Providers are
+// registered with RegisterProvider, usually from an init function, and are
+// loaded when the console boots.
+type CommandProvider interface {
+	Name() string
+	Commands() []*Command
+}
+
+// ProviderSetup is implemented by providers which prepare shared
+// dependencies before their commands are registered.
+type ProviderSetup interface {
+	Setup(c *Console) error
+}
+
+// ProviderPriority is implemented by providers which need to be loaded
+// before or after others. Providers with a lower priority are loaded first,
+// the default priority is 0.
+type ProviderPriority interface {
+	Priority() int
+}
+
+var registry = struct {
+	sync.Mutex
+	commands  []*Command
+	providers []CommandProvider
+}{}
+
+// Register adds commands to the package-level registry. They are added to
+// every console when it boots.
+func Register(commands ...*Command) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.commands = append(registry.commands, commands...)
+}
+
+func RegisterProvider(providers ...CommandProvider) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.providers = append(registry.providers, providers...)
+}
+
+// AddProvider loads a provider into this console only.
+func (c *Console) AddProvider(provider CommandProvider) error {
+	if c.disabledProviders[provider.Name()] {
+		return nil
+	}
+
+	if setup, ok := provider.(ProviderSetup); ok {
+		if err := setup.Setup(c); err != nil {
+			return fmt.Errorf("setup of provider %q: %w", provider.Name(), err)
+		}
+	}
+
+	for _, command := range provider.Commands() {
+		if err := c.addFrom(command, "provider "+provider.Name()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Console) DisableProvider(name string) {
+	if c.disabledProviders == nil {
+		c.disabledProviders = make(map[string]bool)
+	}
+
+	c.disabledProviders[name] = true
+}
+
+func (c *Console) EnableProvider(name string) {
+	delete(c.disabledProviders, name)
+}
+
+// Boot adds the commands and providers of the package-level registry. It is
+// called by Execute, so it is only needed to handle errors early. A failed
+// boot isn't repeated, every later call returns the same error.
+func (c *Console) Boot() error {
+	if c.booted {
+		return c.bootErr
+	}
+	c.booted = true
+
+	c.bootErr = c.boot()
+
+	return c.bootErr
+}
+
+func (c *Console) boot() error {
+
+	registry.Lock()
+	commands := append([]*Command{}, registry.commands...)
+	providers := append([]CommandProvider{}, registry.providers...)
+	registry.Unlock()
+
+	for _, command := range commands {
+		if err := c.addFrom(command, "the registry"); err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providerPriority(providers[i]) < providerPriority(providers[j])
+	})
+
+	for _, provider := range providers {
+		if err := c.AddProvider(provider); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Console) addFrom(command *Command, origin string) error {
+	name := command.GetName()
+
+	// registered commands take precedence over plugins, which may have
+	// been loaded before the boot
+	if _, ok := c.Commands[name]; ok && c.origins[name] != pluginOrigin {
+		existing := c.origins[name]
+		if existing == "" {
+			existing = "the application"
+		}
+
+		return fmt.Errorf("%w: %q from %s is already registered by %s", ErrCommandExists, name, origin, existing)
+	}
+
+	c.Add(command)
+
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	c.origins[name] = origin
+
+	return nil
+}
+
+func providerPriority(provider CommandProvider) int {
+	if p, ok := provider.(ProviderPriority); ok {
+		return p.Priority()
+	}
+
+	return 0
+}
@@ -0,0 +1,117 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testProvider struct {
+	name     string
+	priority int
+	commands []string
+	setup    *[]string
+}
+
+func (p testProvider) Name() string { return p.name }
+
+func (p testProvider) Priority() int { return p.priority }
+
+func (p testProvider) Setup(c *Console) error {
+	*p.setup = append(*p.setup, p.name)
+	return nil
+}
+
+func (p testProvider) Commands() []*Command {
+	var commands []*Command
+	for _, name := range p.commands {
+		commands = append(commands, &Command{Definition: name, Handler: func(ctx *Context) error {
+			ctx.Println(p.name + " " + ctx.GetName())
+			return nil
+		}})
+	}
+
+	return commands
+}
+
+func resetRegistry(t *testing.T) {
+	commands, providers := registry.commands, registry.providers
+	registry.commands, registry.providers = nil, nil
+
+	t.Cleanup(func() {
+		registry.commands, registry.providers = commands, providers
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	resetRegistry(t)
+
+	var setup []string
+	Register(&Command{Definition: "about", Handler: func(ctx *Context) error {
+		ctx.Println("about")
+		return nil
+	}})
+	RegisterProvider(
+		testProvider{name: "db", priority: 10, commands: []string{"db:migrate"}, setup: &setup},
+		testProvider{name: "cache", commands: []string{"cache:clear"}, setup: &setup},
+		testProvider{name: "queue", commands: []string{"queue:work"}, setup: &setup},
+	)
+
+	var out bytes.Buffer
+	cli := New()
+	cli.DisableColors()
+	cli.Output = &out
+	cli.DisableProvider("queue")
+
+	assert.Nil(t, cli.Execute(context.Background(), []string{"db:migrate"}))
+	assert.Nil(t, cli.Execute(context.Background(), []string{"about"}))
+	assert.Equal(t, "db db:migrate\nabout\n", out.String())
+	assert.Equal(t, []string{"cache", "db"}, setup)
+	assert.NotContains(t, cli.Commands, "queue:work")
+}
+
+func TestRegistryCollision(t *testing.T) {
+	resetRegistry(t)
+
+	var setup []string
+	RegisterProvider(
+		testProvider{name: "cache", commands: []string{"clear"}, setup: &setup},
+		testProvider{name: "views", commands: []string{"clear"}, setup: &setup},
+	)
+
+	cli := New()
+	err := cli.Boot()
+
+	assert.True(t, errors.Is(err, ErrCommandExists))
+	assert.Equal(t, `command is already registered: "clear" from provider views is already registered by provider cache`, err.Error())
+
+	// a half booted console doesn't run commands
+	assert.Equal(t, err, cli.Execute(context.Background(), []string{"clear"}))
+
+	cli = New()
+	cli.AddHandler("clear", "", func(*Context) error { return nil })
+	err = cli.AddProvider(testProvider{name: "cache", commands: []string{"clear"}, setup: &setup})
+
+	assert.True(t, errors.Is(err, ErrCommandExists))
+	assert.Contains(t, err.Error(), "already registered by the application")
+}
+
+func TestRegistryRenderAndComplete(t *testing.T) {
+	resetRegistry(t)
+
+	Register(&Command{Definition: "about", Description: "About the application"})
+
+	cli := New()
+	assert.Equal(t, []string{"about"}, cli.Complete("ab"))
+
+	var out bytes.Buffer
+	cli = New()
+	cli.DisableColors()
+	cli.Output = &out
+	cli.Render()
+
+	assert.Contains(t, out.String(), "About the application")
+}
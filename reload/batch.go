@@ -0,0 +1,216 @@
+package reload
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultBuildDelay is the debounce window when BuildDelay isn't set.
+const DefaultBuildDelay = 100 * time.Millisecond
+
+// shownChanges is how many paths a batch lists in the log.
+const shownChanges = 5
+
+// Change is a path which changed within a batch, Op holds all operations
+// seen for it.
+type Change struct {
+	Path string
+	Op   fsnotify.Op
+}
+
+// Batch holds the changes coalesced within the debounce window, in the
+// order they were first seen. A path is listed once.
+type Batch []Change
+
+// Hook is called with every batch before the restart. It returns false
+// when it handled the changes itself, e.g. by regenerating assets, and the
+// application doesn't need to be rebuilt.
+type Hook func(batch Batch) bool
+
+func (b Batch) add(path string, op fsnotify.Op) Batch {
+	for i := range b {
+		if b[i].Path == path {
+			b[i].Op |= op
+			return b
+		}
+	}
+
+	return append(b, Change{Path: path, Op: op})
+}
+
+func (b Batch) merge(other Batch) Batch {
+	for _, change := range other {
+		b = b.add(change.Path, change.Op)
+	}
+
+	return b
+}
+
+// Paths returns the changed paths.
+func (b Batch) Paths() []string {
+	paths := make([]string, 0, len(b))
+	for _, change := range b {
+		paths = append(paths, change.Path)
+	}
+
+	return paths
+}
+
+// String summarizes the batch like "3 files changed: a.go, b.go, c.go".
+func (b Batch) String() string {
+	noun := "files"
+	if len(b) == 1 {
+		noun = "file"
+	}
+
+	paths := b.Paths()
+	if len(paths) > shownChanges {
+		paths = append(paths[:shownChanges], "…")
+	}
+
+	for i, path := range paths {
+		paths[i] = filepath.ToSlash(path)
+	}
+
+	return fmt.Sprintf("%d %s changed: %s", len(b), noun, strings.Join(paths, ", "))
+}
+
+// buildDelay returns the debounce window. A plain number, as written by
+// older configuration files, is a number of milliseconds.
+func (c *Configuration) buildDelay() time.Duration {
+	switch {
+	case c.BuildDelay <= 0:
+		return DefaultBuildDelay
+	case c.BuildDelay < time.Millisecond:
+		return c.BuildDelay * time.Millisecond
+	}
+
+	return c.BuildDelay
+}
+
+// watch collects the changes reported by w into batches. A batch is queued
+// once no change arrived for the debounce window, so a checkout of a branch
+// leads to a single build.
+func (m *Manager) watch(w *Watcher) {
+	delay := m.buildDelay()
+
+	timer := time.NewTimer(delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	var batch Batch
+
+	for {
+		select {
+		case event := <-w.Events():
+			// a created directory isn't a changed file, the files in it are
+			if files := w.trackDirs(event); len(files) > 0 {
+				for _, file := range files {
+					batch = batch.add(file, fsnotify.Create)
+				}
+				resetTimer(timer, delay)
+				continue
+			}
+
+			if !w.isChange(event) {
+				continue
+			}
+
+			if w.isPolling() {
+				w.Remove(event.Name)
+				w.Add(event.Name)
+			}
+
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			if m.isStylesheet(event.Name) {
+				m.liveReload.ReloadCSS(filepath.ToSlash(event.Name))
+				continue
+			}
+
+			batch = batch.add(event.Name, event.Op)
+			resetTimer(timer, delay)
+
+		case <-timer.C:
+			m.queue(batch)
+			batch = nil
+
+		case <-m.context.Done():
+			m.Logger.Info("Shutting down")
+			return
+		}
+	}
+}
+
+// resetTimer restarts the debounce window. A tick which is already in the
+// channel is dropped, so it doesn't end the new window early.
+func resetTimer(timer *time.Timer, delay time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+
+	timer.Reset(delay)
+}
+
+// isChange reports whether an event is about a watched file. Removed files
+// can't be checked on disk anymore.
+func (w *Watcher) isChange(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		return event.Name != "" && w.isWatchedFile(event.Name)
+	}
+
+	return w.isFileEligibleForChange(event.Name)
+}
+
+// queue hands a batch to the runner. Batches which arrive while a build is
+// running are merged, so they lead to one more build.
+func (m *Manager) queue(batch Batch) {
+	if len(batch) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	m.pending = m.pending.merge(batch)
+	m.mu.Unlock()
+
+	select {
+	case m.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) takePending() Batch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	batch := m.pending
+	m.pending = nil
+
+	return batch
+}
+
+// runHooks reports whether the batch needs a restart: without hooks it
+// does, with hooks when one of them asks for it.
+func (m *Manager) runHooks(batch Batch) bool {
+	if len(m.Hooks) == 0 {
+		return true
+	}
+
+	restart := false
+	for _, hook := range m.Hooks {
+		if hook(batch) {
+			restart = true
+		}
+	}
+
+	return restart
+}
@@ -0,0 +1,140 @@
+package reload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchAdd(t *testing.T) {
+	var batch Batch
+	batch = batch.add("a.go", fsnotify.Write)
+	batch = batch.add("b.go", fsnotify.Create)
+	batch = batch.add("a.go", fsnotify.Chmod)
+
+	assert.Equal(t, Batch{{"a.go", fsnotify.Write | fsnotify.Chmod}, {"b.go", fsnotify.Create}}, batch)
+	assert.Equal(t, []string{"a.go", "b.go"}, batch.Paths())
+}
+
+func TestBatchMerge(t *testing.T) {
+	batch := Batch{{"a.go", fsnotify.Write}}
+	batch = batch.merge(Batch{{"b.go", fsnotify.Remove}, {"a.go", fsnotify.Rename}})
+
+	assert.Equal(t, Batch{{"a.go", fsnotify.Write | fsnotify.Rename}, {"b.go", fsnotify.Remove}}, batch)
+	assert.Equal(t, batch, Batch(nil).merge(batch))
+}
+
+func TestBatchString(t *testing.T) {
+	many := Batch{}
+	for i := 1; i <= 7; i++ {
+		many = many.add(fmt.Sprintf("pkg/f%d.go", i), fsnotify.Write)
+	}
+
+	tests := []struct {
+		name  string
+		batch Batch
+		want  string
+	}{
+		{"one file", Batch{{"main.go", fsnotify.Write}}, "1 file changed: main.go"},
+		{"several files", Batch{{"a.go", fsnotify.Write}, {"b.go", fsnotify.Create}}, "2 files changed: a.go, b.go"},
+		{"more than shown", many, "7 files changed: pkg/f1.go, pkg/f2.go, pkg/f3.go, pkg/f4.go, pkg/f5.go, …"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.batch.String(), test.name)
+	}
+}
+
+func TestBuildDelay(t *testing.T) {
+	tests := []struct {
+		delay time.Duration
+		want  time.Duration
+	}{
+		{0, DefaultBuildDelay},
+		{-time.Second, DefaultBuildDelay},
+		{300, 300 * time.Millisecond},
+		{300 * time.Millisecond, 300 * time.Millisecond},
+		{2 * time.Second, 2 * time.Second},
+	}
+
+	for _, test := range tests {
+		c := &Configuration{BuildDelay: test.delay}
+		assert.Equal(t, test.want, c.buildDelay(), test.delay.String())
+	}
+}
+
+func TestQueue(t *testing.T) {
+	m := &Manager{changed: make(chan struct{}, 1)}
+
+	m.queue(nil)
+	assert.Len(t, m.changed, 0, "an empty batch is not queued")
+
+	m.queue(Batch{{"a.go", fsnotify.Write}})
+	m.queue(Batch{{"b.go", fsnotify.Write}, {"a.go", fsnotify.Remove}})
+
+	// batches queued during a build lead to one more build
+	assert.Len(t, m.changed, 1)
+	assert.Equal(t, Batch{{"a.go", fsnotify.Write | fsnotify.Remove}, {"b.go", fsnotify.Write}}, m.takePending())
+	assert.Nil(t, m.takePending())
+}
+
+func TestRunHooks(t *testing.T) {
+	m := &Manager{Configuration: &Configuration{}}
+	assert.True(t, m.runHooks(nil), "restarts without hooks")
+
+	var seen []Batch
+	handled := func(batch Batch) bool { seen = append(seen, batch); return false }
+	restart := func(batch Batch) bool { return true }
+
+	batch := Batch{{"style.scss", fsnotify.Write}}
+
+	m.Hooks = []Hook{handled}
+	assert.False(t, m.runHooks(batch))
+
+	m.Hooks = []Hook{handled, restart}
+	assert.True(t, m.runHooks(batch))
+	assert.Equal(t, []Batch{batch, batch}, seen, "every hook is called")
+}
+
+func TestResetTimerDropsStaleTick(t *testing.T) {
+	timer := time.NewTimer(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	resetTimer(timer, 100*time.Millisecond)
+
+	select {
+	case <-timer.C:
+		t.Fatal("the stale tick ended the window")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-timer.C
+}
+
+func TestWatchBatchesFilesOfCreatedDirectories(t *testing.T) {
+	dir := t.TempDir()
+
+	// a package moved into the application, e.g. by a checkout
+	outside := t.TempDir()
+	mkdirs(t, outside, "shop/cart")
+	writeFile(t, outside, "shop/cart/cart.go", "package cart")
+
+	w := testWatcher(t, &Configuration{AppRoot: dir, BuildDelay: 50 * time.Millisecond})
+	w.Start()
+	go w.watch(w)
+
+	assert.Nil(t, os.Rename(filepath.Join(outside, "shop"), filepath.Join(dir, "shop")))
+
+	select {
+	case <-w.changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch was queued")
+	}
+
+	assert.Equal(t, "1 file changed: "+filepath.ToSlash(filepath.Join(dir, "shop/cart/cart.go")), w.takePending().String())
+}
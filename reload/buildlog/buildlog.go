@@ -0,0 +1,163 @@
+// Package buildlog stores the latest build or run failure of an application
+// managed by reload. The manager writes it and the application, e.g. the
+// web.ErrorChecker middleware, reads it to show the error in the browser.
+package buildlog
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Env is the environment variable the manager passes the path of the log
+// to the application with.
+const Env = "RELOAD_ERROR_LOG"
+
+const (
+	PhaseBuild = "build"
+	PhaseRun   = "run"
+)
+
+// Failure is a failed build or a crashed process. Output is the output of
+// the compiler or the stderr of the process, Dir the directory relative
+// paths in it refer to.
+type Failure struct {
+	Phase  string    `json:"phase"`
+	Dir    string    `json:"dir"`
+	Error  string    `json:"error"`
+	Output string    `json:"output"`
+	Time   time.Time `json:"time"`
+}
+
+// Path returns the log of the application in the working directory, or
+// the one passed by the manager.
+func Path() string {
+	if path := os.Getenv(Env); path != "" {
+		return path
+	}
+
+	dir, _ := os.Getwd()
+
+	return PathFor(dir)
+}
+
+// PathFor returns the log of the application in dir.
+func PathFor(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	return filepath.Join(os.TempDir(), fmt.Sprintf("reload-%x.json", md5.Sum([]byte(dir))))
+}
+
+func Write(path string, failure Failure) error {
+	if failure.Time.IsZero() {
+		failure.Time = time.Now()
+	}
+
+	data, err := json.Marshal(failure)
+	if err != nil {
+		return err
+	}
+
+	// written to a temporary file first, so readers never see half a log
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Clear removes the log after a successful build.
+func Clear(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// Read returns the latest failure, or nil when there is none.
+func Read(path string) (*Failure, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var failure Failure
+	if err := json.Unmarshal(data, &failure); err != nil {
+		return nil, err
+	}
+
+	return &failure, nil
+}
+
+// Location is a position in a source file reported by the compiler.
+type Location struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// ParseLocation parses the location at the start of a compiler message like
+// "./main.go:12:3: undefined: x". Relative files are resolved against dir.
+// It returns the location, the rest of the line and whether there is one.
+func ParseLocation(dir string, line string) (Location, string, bool) {
+	end := strings.Index(line, ".go:")
+	if end < 0 {
+		return Location{}, line, false
+	}
+
+	file := strings.TrimSpace(line[:end+3])
+	if file == "" || strings.ContainsAny(file, " \t") {
+		return Location{}, line, false
+	}
+
+	rest := line[end+4:]
+
+	var numbers []int
+	for len(numbers) < 2 {
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 {
+			break
+		}
+
+		n, _ := strconv.Atoi(rest[:digits])
+		numbers = append(numbers, n)
+		rest = rest[digits:]
+
+		if !strings.HasPrefix(rest, ":") {
+			break
+		}
+		rest = rest[1:]
+	}
+
+	if len(numbers) == 0 {
+		return Location{}, line, false
+	}
+
+	if !filepath.IsAbs(file) && dir != "" {
+		file = filepath.Join(dir, file)
+	}
+
+	location := Location{File: file, Line: numbers[0]}
+	if len(numbers) > 1 {
+		location.Column = numbers[1]
+	}
+
+	return location, rest, true
+}
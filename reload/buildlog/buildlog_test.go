@@ -0,0 +1,61 @@
+package buildlog
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteReadClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.json")
+
+	failure, err := Read(path)
+	assert.Nil(t, err)
+	assert.Nil(t, failure)
+
+	assert.Nil(t, Write(path, Failure{Phase: PhaseBuild, Dir: "/app", Error: "exit status 1", Output: "./main.go:2:14: undefined: x\n"}))
+
+	failure, err = Read(path)
+	assert.Nil(t, err)
+	assert.Equal(t, PhaseBuild, failure.Phase)
+	assert.Equal(t, "./main.go:2:14: undefined: x\n", failure.Output)
+	assert.False(t, failure.Time.IsZero())
+
+	assert.Nil(t, Clear(path))
+	assert.Nil(t, Clear(path))
+
+	failure, err = Read(path)
+	assert.Nil(t, failure)
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv(Env, "")
+	assert.Equal(t, PathFor("."), Path())
+
+	t.Setenv(Env, "/tmp/app.json")
+	assert.Equal(t, "/tmp/app.json", Path())
+}
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		line     string
+		location Location
+		rest     string
+		ok       bool
+	}{
+		{"./main.go:2:14: undefined: x", Location{File: "/app/main.go", Line: 2, Column: 14}, " undefined: x", true},
+		{"\thandlers/user.go:10: missing return", Location{File: "/app/handlers/user.go", Line: 10}, " missing return", true},
+		{"/src/lib/db.go:7:1: syntax error", Location{File: "/src/lib/db.go", Line: 7, Column: 1}, " syntax error", true},
+		{"# example.com/app", Location{}, "# example.com/app", false},
+		{"note: see main.go:3", Location{}, "note: see main.go:3", false},
+		{"main.go: no line", Location{}, "main.go: no line", false},
+	}
+
+	for _, test := range tests {
+		location, rest, ok := ParseLocation("/app", test.line)
+		assert.Equal(t, test.ok, ok, test.line)
+		assert.Equal(t, test.location, location, test.line)
+		assert.Equal(t, test.rest, rest, test.line)
+	}
+}
@@ -0,0 +1,61 @@
+package reload
+
+import (
+	"errors"
+
+	"github.com/evolidev/console"
+)
+
+// Provider adds the reload commands to a console.
+//
+//	console.RegisterProvider(&reload.Provider{})
+type Provider struct{}
+
+func (p *Provider) Name() string { return "reload" }
+
+func (p *Provider) Commands() []*console.Command {
+	return []*console.Command{
+		{
+			Definition:  "reload:explain {path : The file or directory} {--config= : The configuration file}",
+			Description: "Explain why a path is or isn't watched",
+			Handler:     p.explain,
+		},
+	}
+}
+
+func (p *Provider) explain(ctx *console.Context) error {
+	c := &Configuration{Logger: defaultLogger()}
+	if err := loadConfig(c, ctx.GetOptionWithDefault("config", "").String()); err != nil && !errors.Is(err, ErrConfigNotExist) {
+		return err
+	}
+
+	configs := []*Configuration{c}
+	if len(c.Processes) > 0 {
+		for _, process := range c.Processes {
+			process.inherit(c)
+		}
+		configs = c.Processes
+	}
+
+	path := ctx.GetArgument("path").String()
+
+	for _, config := range configs {
+		w := &Watcher{Manager: NewWithContext(config, ctx), context: ctx}
+		w.loadRules()
+
+		decision := w.Explain(path)
+
+		text := ctx.Text(203, decision.String())
+		if decision.Watched {
+			text = ctx.Text(76, decision.String())
+		}
+
+		if config.Name != "" {
+			text = config.Name + ": " + text
+		}
+
+		ctx.Println(text)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"os"
 	"path"
 	"runtime"
@@ -15,25 +16,42 @@ import (
 )
 
 type Configuration struct {
-	AppRoot            string        `yaml:"app_root"`
-	BinaryName         string        `yaml:"binary_name"`
-	BuildDelay         time.Duration `yaml:"build_delay"`
-	BuildFlags         []string      `yaml:"build_flags"`
-	BuildPath          string        `yaml:"build_path"`
-	BuildTargetPath    string        `yaml:"build_target_path"`
-	Command            string        `yaml:"command"`
-	CommandEnv         []string      `yaml:"command_env"`
-	CommandFlags       []string      `yaml:"command_flags"`
-	EnableColors       bool          `yaml:"enable_colors"`
-	ForcePolling       bool          `yaml:"force_polling,omitempty"`
-	IgnoredFolders     []string      `yaml:"ignored_folders"`
-	IncludedExtensions []string      `yaml:"included_extensions"`
-	LogName            string        `yaml:"log_name"`
-	Debug              bool          `yaml:"-"`
-	Path               string        `yaml:"-"`
-	Stderr             io.Writer     `yaml:"-"`
-	Stdin              io.Reader     `yaml:"-"`
-	Stdout             io.Writer     `yaml:"-"`
+	AppRoot            string           `yaml:"app_root"`
+	BinaryName         string           `yaml:"binary_name"`
+	BuildCommand       string           `yaml:"build_command"`
+	BuildDelay         time.Duration    `yaml:"build_delay"`
+	BuildFlags         []string         `yaml:"build_flags"`
+	BuildPath          string           `yaml:"build_path"`
+	BuildTargetPath    string           `yaml:"build_target_path"`
+	Command            string           `yaml:"command"`
+	CommandEnv         []string         `yaml:"command_env"`
+	CommandFlags       []string         `yaml:"command_flags"`
+	DependsOn          []string         `yaml:"depends_on"`
+	DisableGitignore   bool             `yaml:"disable_gitignore"`
+	EnableColors       bool             `yaml:"enable_colors"`
+	Exclude            []string         `yaml:"exclude"`
+	ForcePolling       bool             `yaml:"force_polling,omitempty"`
+	IgnoredFolders     []string         `yaml:"ignored_folders"`
+	Include            []string         `yaml:"include"`
+	IncludedExtensions []string         `yaml:"included_extensions"`
+	LiveReload         string           `yaml:"live_reload"`
+	LogName            string           `yaml:"log_name"`
+	Name               string           `yaml:"name"`
+	Processes          []*Configuration `yaml:"processes"`
+	Proxy              string           `yaml:"proxy"`
+	ProxyTarget        string           `yaml:"proxy_target"`
+	ProxyTimeout       time.Duration    `yaml:"proxy_timeout"`
+	Ready              ReadyCheck       `yaml:"ready"`
+	StopSignal         string           `yaml:"stop_signal"`
+	StopTimeout        time.Duration    `yaml:"stop_timeout"`
+	Watch              []string         `yaml:"watch"`
+	Debug              bool             `yaml:"-"`
+	Hooks              []Hook           `yaml:"-"`
+	Logger             *slog.Logger     `yaml:"-"`
+	Path               string           `yaml:"-"`
+	Stderr             io.Writer        `yaml:"-"`
+	Stdin              io.Reader        `yaml:"-"`
+	Stdout             io.Writer        `yaml:"-"`
 }
 
 func (c *Configuration) FullBuildPath() string {
@@ -46,6 +64,76 @@ func (c *Configuration) FullBuildPath() string {
 	return buildPath
 }
 
+// setDefaults fills in what a configuration without a file needs: the
+// application in the working directory is built into the temp dir.
+func (c *Configuration) setDefaults() {
+	if c.AppRoot == "" {
+		c.AppRoot = "."
+	}
+
+	if c.BinaryName == "" && c.Command == "" {
+		c.BinaryName = "reload-build"
+		if c.Name != "" {
+			c.BinaryName += "-" + c.Name
+		}
+		if c.BuildPath == "" {
+			c.BuildPath = os.TempDir()
+		}
+	}
+
+	if len(c.IncludedExtensions) == 0 {
+		c.IncludedExtensions = []string{".go"}
+	}
+}
+
+// inherit fills in the settings a process shares with the configuration it
+// is listed in. The live reload server and the proxy are not shared, their
+// addresses can only be used once.
+func (c *Configuration) inherit(parent *Configuration) {
+	if c.AppRoot == "" {
+		c.AppRoot = parent.AppRoot
+	}
+
+	if len(c.IgnoredFolders) == 0 {
+		c.IgnoredFolders = parent.IgnoredFolders
+	}
+
+	if len(c.IncludedExtensions) == 0 {
+		c.IncludedExtensions = parent.IncludedExtensions
+	}
+
+	if len(c.Include) == 0 {
+		c.Include = parent.Include
+	}
+
+	if c.BuildDelay == 0 {
+		c.BuildDelay = parent.BuildDelay
+	}
+
+	if c.StopSignal == "" {
+		c.StopSignal = parent.StopSignal
+	}
+
+	if c.StopTimeout == 0 {
+		c.StopTimeout = parent.StopTimeout
+	}
+
+	c.CommandEnv = append(append([]string{}, parent.CommandEnv...), c.CommandEnv...)
+	c.Exclude = append(append([]string{}, parent.Exclude...), c.Exclude...)
+	c.DisableGitignore = c.DisableGitignore || parent.DisableGitignore
+	c.EnableColors = c.EnableColors || parent.EnableColors
+	c.ForcePolling = c.ForcePolling || parent.ForcePolling
+	c.Debug = c.Debug || parent.Debug
+
+	if c.Logger == nil {
+		c.Logger = parent.Logger
+	}
+
+	if len(c.Hooks) == 0 {
+		c.Hooks = parent.Hooks
+	}
+}
+
 func (c *Configuration) Load(path string) error {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
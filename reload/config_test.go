@@ -0,0 +1,38 @@
+package reload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInherit(t *testing.T) {
+	parent := &Configuration{
+		AppRoot:        "app",
+		IgnoredFolders: []string{"vendor"},
+		BuildDelay:     300 * time.Millisecond,
+		CommandEnv:     []string{"APP_ENV=local"},
+		Exclude:        []string{"**/*_test.go"},
+		StopTimeout:    time.Second,
+		ForcePolling:   true,
+	}
+
+	p := &Configuration{Name: "worker", CommandEnv: []string{"QUEUE=mail"}, StopTimeout: 5 * time.Second}
+	p.inherit(parent)
+
+	assert.Equal(t, "app", p.AppRoot)
+	assert.Equal(t, []string{"vendor"}, p.IgnoredFolders)
+	assert.Equal(t, 300*time.Millisecond, p.BuildDelay)
+	assert.Equal(t, []string{"APP_ENV=local", "QUEUE=mail"}, p.CommandEnv)
+	assert.Equal(t, []string{"**/*_test.go"}, p.Exclude)
+	assert.Equal(t, 5*time.Second, p.StopTimeout)
+	assert.True(t, p.ForcePolling)
+
+	// the processes of a group watch their files unless told otherwise
+	assert.False(t, p.Debug)
+
+	parent.Debug = true
+	p.inherit(parent)
+	assert.True(t, p.Debug)
+}
@@ -0,0 +1,213 @@
+package reload
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evolidev/console/reload/glob"
+)
+
+// defaultExcludes skips hidden directories like .git and directories
+// starting with an underscore.
+var defaultExcludes = []string{".*/", "_*/"}
+
+// Decision tells whether a path is watched and why. For a directory Watched
+// means it is walked.
+type Decision struct {
+	Path    string
+	Watched bool
+	Reason  string
+}
+
+func (d Decision) String() string {
+	if d.Watched {
+		return fmt.Sprintf("%s is watched: %s", d.Path, d.Reason)
+	}
+
+	return fmt.Sprintf("%s is not watched: %s", d.Path, d.Reason)
+}
+
+// loadRules compiles the exclude and include rules: the defaults,
+// IgnoredFolders, the .gitignore files below AppRoot and Exclude, in this
+// order, so a later rule overrides an earlier one.
+func (w *Watcher) loadRules() {
+	w.exclude = nil
+	for _, text := range defaultExcludes {
+		w.addExclude(text, "", "default")
+	}
+
+	for _, folder := range w.IgnoredFolders {
+		if folder = strings.TrimSpace(folder); folder != "" {
+			w.addExclude(strings.TrimSuffix(folder, "/")+"/", "", "ignored_folders")
+		}
+	}
+
+	var excludes []glob.Pattern
+	for _, text := range w.Exclude {
+		if p, ok := glob.ParsePattern(text, "", "exclude"); ok {
+			excludes = append(excludes, p)
+		}
+	}
+
+	if !w.DisableGitignore {
+		w.loadGitignores(excludes)
+	}
+
+	w.exclude = append(w.exclude, excludes...)
+
+	w.include = nil
+	for _, text := range w.Include {
+		if p, ok := glob.ParsePattern(text, "", "include"); ok {
+			w.include = append(w.include, p)
+		}
+	}
+}
+
+func (w *Watcher) addExclude(text, base, source string) {
+	if p, ok := glob.ParsePattern(text, base, source); ok {
+		w.exclude = append(w.exclude, p)
+	}
+}
+
+// loadGitignores reads the .gitignore files of the directories which aren't
+// excluded, a nested file applies to its directory only.
+func (w *Watcher) loadGitignores(excludes []glob.Pattern) {
+	err := filepath.WalkDir(w.AppRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+
+		rel := w.relative(path)
+		if rel != "" {
+			// the rules of the configuration win over the .gitignore files
+			if _, ok := glob.Excluding(append(w.exclude, excludes...), rel, true); ok {
+				return filepath.SkipDir
+			}
+		}
+
+		file, err := os.Open(filepath.Join(path, ".gitignore"))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		source := ".gitignore"
+		if rel != "" {
+			source = rel + "/.gitignore"
+		}
+
+		patterns, err := glob.ParseGitignore(file, rel, source)
+		w.exclude = append(w.exclude, patterns...)
+
+		return err
+	})
+	if err != nil {
+		w.Logger.Warn("Read .gitignore", "error", err)
+	}
+}
+
+// relative returns path relative to AppRoot with forward slashes, "" for
+// AppRoot itself and paths outside of it.
+func (w *Watcher) relative(path string) string {
+	root, err := filepath.Abs(w.AppRoot)
+	if err != nil {
+		return ""
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// Explain tells whether a changed path restarts the application and why.
+func (w *Watcher) Explain(path string) Decision {
+	info, err := os.Stat(path)
+
+	return w.decide(path, err == nil && info.IsDir())
+}
+
+func (w *Watcher) decide(path string, isDir bool) Decision {
+	d := Decision{Path: path}
+
+	rel := w.relative(path)
+	if rel == "" {
+		if isDir && filepath.Clean(path) == filepath.Clean(w.AppRoot) {
+			d.Watched, d.Reason = true, "it is app_root"
+		} else {
+			d.Reason = "it is outside of app_root " + w.AppRoot
+		}
+		return d
+	}
+
+	if len(w.Watch) > 0 && !w.inWatchPaths(rel, isDir) {
+		d.Reason = "it is outside of the watch paths " + strings.Join(w.Watch, ", ")
+		return d
+	}
+
+	if p, ok := glob.Excluding(w.exclude, rel, isDir); ok {
+		d.Reason = fmt.Sprintf("excluded by %q (%s)", p.Text, p.Source)
+		return d
+	}
+
+	if isDir {
+		d.Watched, d.Reason = true, "the directory is not excluded"
+		return d
+	}
+
+	if w.isStylesheet(path) {
+		d.Watched, d.Reason = true, "stylesheets are swapped by live reload"
+		return d
+	}
+
+	if len(w.Include) > 0 {
+		if p, ok := glob.Last(w.include, rel, false); ok && !p.Negate {
+			d.Watched, d.Reason = true, fmt.Sprintf("included by %q", p.Text)
+		} else {
+			d.Reason = "it matches no include pattern"
+		}
+		return d
+	}
+
+	ext := filepath.Ext(path)
+	for _, e := range w.IncludedExtensions {
+		if strings.TrimSpace(e) == ext {
+			d.Watched, d.Reason = true, "the extension "+ext+" is included"
+			return d
+		}
+	}
+
+	d.Reason = fmt.Sprintf("the extension %q is not in included_extensions", ext)
+	return d
+}
+
+// inWatchPaths reports whether rel is in one of the watch paths, or is a
+// directory on the way to one.
+func (w *Watcher) inWatchPaths(rel string, isDir bool) bool {
+	for _, path := range w.Watch {
+		root := filepath.ToSlash(filepath.Clean(path))
+		if root == "." || rel == root || strings.HasPrefix(rel, root+"/") {
+			return true
+		}
+
+		if isDir && strings.HasPrefix(root, rel+"/") {
+			return true
+		}
+	}
+
+	return false
+}
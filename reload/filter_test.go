@@ -0,0 +1,109 @@
+package reload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecide(t *testing.T) {
+	dir := t.TempDir()
+	mkdirs(t, dir, "api/gen", "gen", "vendor", "_tools", "_scripts")
+	writeFile(t, dir, ".gitignore", "*.log\n!keep.log\n!_tools/\n!_scripts/\n!vendor/\n")
+	writeFile(t, dir, "api/.gitignore", "gen/\n!gen/keep.go\n")
+
+	w := testWatcher(t, &Configuration{
+		AppRoot:        dir,
+		IgnoredFolders: []string{"node_modules", "vendor"},
+		Exclude:        []string{"_scripts/", "debug.log"},
+		Include:        []string{"**/*.go", "templates/**/*.html"},
+	})
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		watched bool
+		reason  string
+	}{
+		{".", true, true, "it is app_root"},
+		{"../other.go", false, false, "it is outside of app_root " + dir},
+
+		// defaults
+		{".git", true, false, `excluded by ".*/" (default)`},
+		{"_build/main.go", false, false, `excluded by "_*/" (default)`},
+
+		// ignored_folders
+		{"node_modules/lib/index.go", false, false, `excluded by "node_modules/" (ignored_folders)`},
+
+		// .gitignore files override ignored_folders and the defaults
+		{"_tools", true, true, "the directory is not excluded"},
+		{"vendor/lib/lib.go", false, true, `included by "**/*.go"`},
+		{"server.log", false, false, `excluded by "*.log" (.gitignore:1)`},
+
+		// a nested .gitignore applies to its directory only
+		{"api/gen/types.go", false, false, `excluded by "gen/" (api/.gitignore:1)`},
+		{"gen/types.go", false, true, `included by "**/*.go"`},
+
+		// a file in an excluded directory can't be re-included
+		{"api/gen/keep.go", false, false, `excluded by "gen/" (api/.gitignore:1)`},
+
+		// exclude overrides the .gitignore files
+		{"_scripts", true, false, `excluded by "_scripts/" (exclude)`},
+		{"debug.log", false, false, `excluded by "debug.log" (exclude)`},
+		{"keep.log", false, false, "it matches no include pattern"},
+
+		// include decides for the files which aren't excluded
+		{"api/main.go", false, true, `included by "**/*.go"`},
+		{"templates/mail/welcome.html", false, true, `included by "templates/**/*.html"`},
+		{"README.md", false, false, "it matches no include pattern"},
+	}
+
+	for _, test := range tests {
+		d := w.decide(filepath.Join(dir, test.path), test.isDir)
+		assert.Equal(t, test.watched, d.Watched, test.path)
+		assert.Equal(t, test.reason, d.Reason, test.path)
+	}
+}
+
+func TestDecideWatchRoots(t *testing.T) {
+	dir := t.TempDir()
+
+	w := testWatcher(t, &Configuration{AppRoot: dir, Watch: []string{"api", "cmd/worker"}})
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		watched bool
+		reason  string
+	}{
+		{"api", true, true, "the directory is not excluded"},
+		{"api/v1/users.go", false, true, "the extension .go is included"},
+		{"api/v1/users.txt", false, false, `the extension ".txt" is not in included_extensions`},
+		{"cmd", true, true, "the directory is not excluded"},
+		{"cmd/worker/main.go", false, true, "the extension .go is included"},
+		{"cmd/api/main.go", false, false, "it is outside of the watch paths api, cmd/worker"},
+		{"main.go", false, false, "it is outside of the watch paths api, cmd/worker"},
+		{"apis", true, false, "it is outside of the watch paths api, cmd/worker"},
+	}
+
+	for _, test := range tests {
+		d := w.decide(filepath.Join(dir, test.path), test.isDir)
+		assert.Equal(t, test.watched, d.Watched, test.path)
+		assert.Equal(t, test.reason, d.Reason, test.path)
+	}
+}
+
+func TestDecideWithoutGitignore(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".gitignore", "gen/\n")
+
+	w := testWatcher(t, &Configuration{AppRoot: dir, DisableGitignore: true})
+
+	assert.True(t, w.decide(filepath.Join(dir, "gen/types.go"), false).Watched)
+}
+
+func writeFile(t *testing.T, root string, path string, content string) {
+	assert.Nil(t, os.WriteFile(filepath.Join(root, path), []byte(content), 0644))
+}
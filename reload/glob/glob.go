@@ -0,0 +1,187 @@
+// Package glob matches slash separated paths against doublestar globs and
+// gitignore-style patterns, as used by the reload watcher.
+package glob
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Match reports whether name matches pattern. Both are slash separated. In
+// addition to the syntax of path.Match a "**" segment matches any number of
+// directories, including none. An invalid pattern never matches.
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// collapse repeated ** segments
+			for len(pattern) > 1 && pattern[1] == "**" {
+				pattern = pattern[1:]
+			}
+
+			if len(pattern) == 1 {
+				return true
+			}
+
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// Pattern is a gitignore-style pattern. A pattern without a slash matches at
+// any depth below Base, a pattern ending with a slash only matches
+// directories and a pattern starting with "!" re-includes what a previous
+// pattern excluded. Source tells where the pattern was defined, e.g.
+// ".gitignore:3".
+type Pattern struct {
+	Text    string
+	Base    string
+	Source  string
+	Negate  bool
+	DirOnly bool
+
+	glob string
+}
+
+// ParsePattern parses a line of a .gitignore file in the directory base,
+// relative to the root. It returns false for blank lines and comments.
+func ParsePattern(line, base, source string) (Pattern, bool) {
+	text := strings.TrimRight(line, " \t\r")
+	if text == "" || strings.HasPrefix(text, "#") {
+		return Pattern{}, false
+	}
+
+	p := Pattern{Text: text, Base: strings.Trim(base, "/"), Source: source}
+
+	if strings.HasPrefix(text, "!") {
+		p.Negate = true
+		text = text[1:]
+	}
+	text = strings.TrimPrefix(text, `\`)
+
+	if strings.HasSuffix(text, "/") {
+		p.DirOnly = true
+		text = strings.TrimRight(text, "/")
+	}
+
+	if text == "" {
+		return Pattern{}, false
+	}
+
+	if strings.Contains(text, "/") {
+		text = strings.TrimPrefix(text, "/")
+	} else {
+		text = "**/" + text
+	}
+
+	p.glob = text
+
+	return p, true
+}
+
+// ParseGitignore parses a .gitignore file in the directory base.
+func ParseGitignore(r io.Reader, base, file string) ([]Pattern, error) {
+	var patterns []Pattern
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		if p, ok := ParsePattern(scanner.Text(), base, fmt.Sprintf("%s:%d", file, n)); ok {
+			patterns = append(patterns, p)
+		}
+	}
+
+	return patterns, scanner.Err()
+}
+
+// Match reports whether the pattern matches name, a slash separated path
+// relative to the root. A pattern which matches a directory also matches
+// everything in it.
+func (p Pattern) Match(name string, isDir bool) bool {
+	segments := strings.Split(name, "/")
+	for i := 1; i <= len(segments); i++ {
+		if p.matchPath(strings.Join(segments[:i], "/"), i < len(segments) || isDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPath reports whether the pattern matches name itself, not one of its
+// parent directories.
+func (p Pattern) matchPath(name string, isDir bool) bool {
+	if p.DirOnly && !isDir {
+		return false
+	}
+
+	if p.Base != "" {
+		if !strings.HasPrefix(name, p.Base+"/") {
+			return false
+		}
+		name = name[len(p.Base)+1:]
+	}
+
+	return Match(p.glob, name)
+}
+
+// Last returns the last pattern which matches name or one of its parent
+// directories, e.g. to decide whether name is in a list of includes. ok is
+// false when no pattern matches.
+func Last(patterns []Pattern, name string, isDir bool) (Pattern, bool) {
+	for i := len(patterns) - 1; i >= 0; i-- {
+		if patterns[i].Match(name, isDir) {
+			return patterns[i], true
+		}
+	}
+
+	return Pattern{}, false
+}
+
+// Excluding returns the pattern which excludes name, like git does: the
+// last pattern matching name decides, but "!" can't re-include a path when
+// one of its parent directories is excluded. ok is false when name isn't
+// excluded.
+func Excluding(patterns []Pattern, name string, isDir bool) (Pattern, bool) {
+	segments := strings.Split(name, "/")
+	for i := 1; i <= len(segments); i++ {
+		dir := i < len(segments) || isDir
+
+		for j := len(patterns) - 1; j >= 0; j-- {
+			if !patterns[j].matchPath(strings.Join(segments[:i], "/"), dir) {
+				continue
+			}
+
+			if !patterns[j].Negate {
+				return patterns[j], true
+			}
+			break
+		}
+	}
+
+	return Pattern{}, false
+}
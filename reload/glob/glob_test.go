@@ -0,0 +1,94 @@
+package glob
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/api/main.go", true},
+		{"**/*_test.go", "cmd/api/main_test.go", true},
+		{"**/*_test.go", "cmd/api/main.go", false},
+		{"internal/**/testdata/**", "internal/a/b/testdata/x.json", true},
+		{"internal/**/testdata/**", "internal/testdata/x.json", true},
+		{"internal/**/testdata/**", "pkg/testdata/x.json", false},
+		{"**/gen/**", "api/v1/gen/types.go", true},
+		{"cmd/**", "cmd", true},
+		{"cmd/*", "cmd/api/main.go", false},
+		{"[", "[", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Match(test.pattern, test.name), "%s %s", test.pattern, test.name)
+	}
+}
+
+func TestGitignore(t *testing.T) {
+	patterns, err := ParseGitignore(strings.NewReader("# generated\n\ngen/\n/tmp\n*.log\n!keep.log\n"), "", ".gitignore")
+	assert.Nil(t, err)
+	assert.Len(t, patterns, 4)
+
+	nested, _ := ParseGitignore(strings.NewReader("*.json\n"), "web", "web/.gitignore")
+	patterns = append(patterns, nested...)
+
+	excluded := func(name string, isDir bool) bool {
+		_, ok := Excluding(patterns, name, isDir)
+		return ok
+	}
+
+	assert.True(t, excluded("gen", true))
+	assert.False(t, excluded("gen", false))
+	assert.True(t, excluded("api/v1/gen/types.go", false))
+	assert.True(t, excluded("tmp/build", false))
+	assert.False(t, excluded("cmd/tmp/main.go", false))
+	assert.True(t, excluded("logs/app.log", false))
+	assert.False(t, excluded("keep.log", false))
+	assert.True(t, excluded("web/package.json", false))
+	assert.False(t, excluded("package.json", false))
+
+	p, _ := Excluding(patterns, "api/v1/gen/types.go", false)
+	assert.Equal(t, ".gitignore:3", p.Source)
+	assert.Equal(t, "gen/", p.Text)
+}
+
+func TestExcludingNegation(t *testing.T) {
+	patterns, err := ParseGitignore(strings.NewReader("gen/\n!gen/keep.go\nbuild/*\n!build/keep/\n*.tmp\n!important.tmp\n"), "", ".gitignore")
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		isDir    bool
+		excluded bool
+	}{
+		// a parent directory is excluded, git doesn't look into it
+		{"gen/keep.go", false, true},
+		{"api/gen/keep.go", false, true},
+		// only the files in build are excluded, so one can be re-included
+		{"build", true, false},
+		{"build/app", false, true},
+		{"build/keep", true, false},
+		{"build/keep/app", false, false},
+		{"important.tmp", false, false},
+		{"logs/important.tmp", false, false},
+		{"logs/other.tmp", false, true},
+	}
+
+	for _, test := range tests {
+		_, ok := Excluding(patterns, test.name, test.isDir)
+		assert.Equal(t, test.excluded, ok, test.name)
+	}
+
+	// Last still lets the later pattern win
+	p, _ := Last(patterns, "gen/keep.go", false)
+	assert.True(t, p.Negate)
+}
@@ -0,0 +1,179 @@
+package reload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/evolidev/console/color"
+)
+
+// processColors are the colors of the process names in the output.
+var processColors = []int{39, 170, 214, 76, 141, 203, 45, 220}
+
+// Group runs the processes of a configuration which lists several, each
+// with its own manager. A process starts once the processes it depends on
+// are ready and restarts whenever one of them restarted.
+type Group struct {
+	Managers   []*Manager
+	cancelFunc context.CancelFunc
+}
+
+func NewGroup(c *Configuration, ctx context.Context) (*Group, error) {
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	if c.Logger == nil {
+		c.Logger = defaultLogger()
+	}
+
+	stdout, stderr := c.Stdout, c.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	width := 0
+	for _, p := range c.Processes {
+		width = max(width, len(p.Name))
+	}
+
+	// lines of different processes must not be mixed
+	var mu sync.Mutex
+
+	g := &Group{cancelFunc: cancelFunc}
+	managers := make(map[string]*Manager)
+
+	for i, p := range c.Processes {
+		if p.Name == "" {
+			cancelFunc()
+			return nil, fmt.Errorf("process %d has no name", i+1)
+		}
+
+		if _, ok := managers[p.Name]; ok {
+			cancelFunc()
+			return nil, fmt.Errorf("process %s is defined twice", p.Name)
+		}
+
+		p.inherit(c)
+		p.Logger = p.Logger.With("process", p.Name)
+
+		prefix := fmt.Sprintf("%-*s | ", width, p.Name)
+		if p.EnableColors {
+			prefix = color.Text(processColors[i%len(processColors)], prefix)
+		}
+
+		if p.Stdout == nil {
+			p.Stdout = &prefixWriter{mu: &mu, out: stdout, prefix: []byte(prefix)}
+		}
+		if p.Stderr == nil {
+			p.Stderr = &prefixWriter{mu: &mu, out: stderr, prefix: []byte(prefix)}
+		}
+
+		m := NewWithContext(p, ctx)
+		managers[p.Name] = m
+		g.Managers = append(g.Managers, m)
+	}
+
+	for _, m := range g.Managers {
+		for _, name := range m.DependsOn {
+			dependency, ok := managers[name]
+			if !ok {
+				cancelFunc()
+				return nil, fmt.Errorf("process %s depends on unknown process %s", m.Name, name)
+			}
+
+			m.dependsOn = append(m.dependsOn, dependency)
+			dependency.dependents = append(dependency.dependents, m)
+		}
+	}
+
+	for _, m := range g.Managers {
+		if cycle := dependencyCycle(m, nil); cycle != nil {
+			cancelFunc()
+			return nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	return g, nil
+}
+
+// Start runs all processes until the context is done. When one of them
+// can't be started the others are stopped.
+func (g *Group) Start() error {
+	errs := make(chan error, len(g.Managers))
+	for _, m := range g.Managers {
+		go func(m *Manager) {
+			err := m.Start()
+			if err != nil {
+				err = fmt.Errorf("%s: %w", m.Name, err)
+				g.cancelFunc()
+			}
+			errs <- err
+		}(m)
+	}
+
+	var all []error
+	for range g.Managers {
+		all = append(all, <-errs)
+	}
+
+	return errors.Join(all...)
+}
+
+// Stop stops all processes.
+func (g *Group) Stop() {
+	g.cancelFunc()
+}
+
+// dependencyCycle returns the names of a cycle m is part of, or nil.
+func dependencyCycle(m *Manager, path []string) []string {
+	for i, name := range path {
+		if name == m.Name {
+			return append(path[i:], m.Name)
+		}
+	}
+
+	path = append(path, m.Name)
+	for _, dependency := range m.dependsOn {
+		if cycle := dependencyCycle(dependency, path); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// prefixWriter writes complete lines prefixed with the name of a process.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix []byte
+	line   []byte
+}
+
+func (w *prefixWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.line = append(w.line, b...)
+
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+
+		_, err := w.out.Write(append(append([]byte{}, w.prefix...), w.line[:i+1]...))
+		w.line = w.line[i+1:]
+		if err != nil {
+			return len(b), err
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package reload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"one line", []string{"listening\n"}, "api | listening\n"},
+		{"several lines", []string{"a\nb\n"}, "api | a\napi | b\n"},
+		{"split line", []string{"list", "ening\n"}, "api | listening\n"},
+		{"unfinished line", []string{"a\nb"}, "api | a\n"},
+		{"empty line", []string{"\n"}, "api | \n"},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		w := &prefixWriter{mu: &sync.Mutex{}, out: out, prefix: []byte("api | ")}
+
+		for _, write := range test.writes {
+			n, err := w.Write([]byte(write))
+			assert.Nil(t, err)
+			assert.Equal(t, len(write), n)
+		}
+
+		assert.Equal(t, test.want, out.String(), test.name)
+	}
+}
+
+func TestPrefixWriterDoesNotMixLines(t *testing.T) {
+	var mu sync.Mutex
+	out := &bytes.Buffer{}
+	api := &prefixWriter{mu: &mu, out: out, prefix: []byte("api | ")}
+	worker := &prefixWriter{mu: &mu, out: out, prefix: []byte("worker | ")}
+
+	api.Write([]byte("start"))
+	worker.Write([]byte("job done\n"))
+	api.Write([]byte("ed\n"))
+
+	assert.Equal(t, "worker | job done\napi | started\n", out.String())
+}
+
+func TestPrefixWriterError(t *testing.T) {
+	w := &prefixWriter{mu: &sync.Mutex{}, out: failingWriter{}, prefix: []byte("api | ")}
+
+	n, err := w.Write([]byte("a\nb\n"))
+	assert.Equal(t, 4, n)
+	assert.EqualError(t, err, "closed")
+}
+
+func TestDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		dependsOn map[string][]string
+		want      []string
+	}{
+		{"no dependencies", map[string][]string{"api": nil}, nil},
+		{"chain", map[string][]string{"api": {"db"}, "db": {"cache"}, "cache": nil}, nil},
+		{"shared dependency", map[string][]string{"api": {"db", "cache"}, "db": {"cache"}, "cache": nil}, nil},
+		{"self", map[string][]string{"api": {"api"}}, []string{"api", "api"}},
+		{"cycle", map[string][]string{"api": {"db"}, "db": {"worker"}, "worker": {"db"}}, []string{"db", "worker", "db"}},
+	}
+
+	for _, test := range tests {
+		managers := make(map[string]*Manager)
+		for name := range test.dependsOn {
+			managers[name] = &Manager{Configuration: &Configuration{Name: name}}
+		}
+		for name, dependencies := range test.dependsOn {
+			for _, dependency := range dependencies {
+				managers[name].dependsOn = append(managers[name].dependsOn, managers[dependency])
+			}
+		}
+
+		assert.Equal(t, test.want, dependencyCycle(managers["api"], nil), test.name)
+	}
+}
+
+func TestNewGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		processes []*Configuration
+		err       string
+	}{
+		{"missing name", []*Configuration{{Name: "api"}, {}}, "process 2 has no name"},
+		{"duplicate name", []*Configuration{{Name: "api"}, {Name: "api"}}, "process api is defined twice"},
+		{"unknown dependency", []*Configuration{{Name: "api", DependsOn: []string{"db"}}}, "process api depends on unknown process db"},
+		{"cycle", []*Configuration{{Name: "api", DependsOn: []string{"worker"}}, {Name: "worker", DependsOn: []string{"api"}}}, "dependency cycle: api -> worker -> api"},
+	}
+
+	for _, test := range tests {
+		_, err := NewGroup(groupConfiguration(test.processes...), context.Background())
+		assert.EqualError(t, err, test.err, test.name)
+	}
+
+	c := groupConfiguration(&Configuration{Name: "api", Command: "true"}, &Configuration{Name: "worker", Command: "true", DependsOn: []string{"api"}})
+	c.AppRoot = "app"
+
+	g, err := NewGroup(c, context.Background())
+	assert.Nil(t, err)
+	defer g.Stop()
+
+	api, worker := g.Managers[0], g.Managers[1]
+	assert.Equal(t, []*Manager{api}, worker.dependsOn)
+	assert.Equal(t, []*Manager{worker}, api.dependents)
+	assert.Equal(t, "app", worker.AppRoot)
+	assert.NotEqual(t, api.ErrorLog, worker.ErrorLog)
+
+	worker.Stdout.Write([]byte("job done\n"))
+	assert.Equal(t, "worker | job done\n", c.Stdout.(*bytes.Buffer).String())
+}
+
+func groupConfiguration(processes ...*Configuration) *Configuration {
+	return &Configuration{
+		Processes: processes,
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Stdout:    &bytes.Buffer{},
+		Stderr:    &bytes.Buffer{},
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(b []byte) (int, error) {
+	return 0, errors.New("closed")
+}
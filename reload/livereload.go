@@ -0,0 +1,51 @@
+package reload
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/evolidev/console/reload/web"
+)
+
+// serveLiveReload starts the live reload server the pages of the
+// application connect to.
+func (m *Manager) serveLiveReload() error {
+	listener, err := net.Listen("tcp", m.LiveReload)
+	if err != nil {
+		return err
+	}
+
+	m.liveReload = web.NewLiveReload()
+	m.liveReloadURL = "http://" + browserAddress(listener.Addr().(*net.TCPAddr))
+
+	server := &http.Server{Handler: m.liveReload}
+	go server.Serve(listener)
+	go func() {
+		<-m.context.Done()
+		server.Close()
+	}()
+
+	m.Logger.Info("Live reload", "url", m.liveReloadURL)
+
+	return nil
+}
+
+// browserAddress returns an address the browser can connect to, also when
+// the server listens on all interfaces.
+func browserAddress(addr *net.TCPAddr) string {
+	host := addr.IP.String()
+	if addr.IP.IsUnspecified() {
+		host = "localhost"
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(addr.Port))
+}
+
+// isStylesheet reports whether a change is swapped in the browser instead
+// of restarting the application.
+func (m *Manager) isStylesheet(path string) bool {
+	return m.liveReload != nil && strings.EqualFold(filepath.Ext(path), ".css")
+}
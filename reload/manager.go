@@ -2,26 +2,44 @@ package reload
 
 import (
 	"context"
-	"github.com/evolidev/logging"
-	"github.com/evolidev/use"
-	"log"
+	"errors"
+	"fmt"
+	"github.com/evolidev/console"
+	"github.com/evolidev/console/reload/buildlog"
+	"github.com/evolidev/console/reload/web"
+	"io"
+	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/fsnotify/fsnotify"
 )
 
 type Manager struct {
 	*Configuration
 	ID         string
-	Logger     *logging.Logger
+	ErrorLog   string
+	Logger     *slog.Logger
 	Restart    chan bool
 	cancelFunc context.CancelFunc
 	context    context.Context
 	gil        *sync.Once
-	cmd        *exec.Cmd
+	mu         sync.Mutex
+	process    *process
+
+	liveReload    *web.LiveReload
+	liveReloadURL string
+	proxy         *web.Proxy
+
+	dependsOn  []*Manager
+	dependents []*Manager
+	readyOnce  sync.Once
+	readyCh    chan struct{}
+
+	changed chan struct{}
+	pending Batch
 }
 
 func New(c *Configuration) *Manager {
@@ -30,96 +48,306 @@ func New(c *Configuration) *Manager {
 
 func NewWithContext(c *Configuration, ctx context.Context) *Manager {
 	ctx, cancelFunc := context.WithCancel(ctx)
+	c.setDefaults()
+
+	logger := c.Logger
+	if logger == nil {
+		logger = defaultLogger()
+	}
+
+	errorLog := buildlog.PathFor(c.AppRoot)
+	if c.Name != "" {
+		// the processes of a group report their failures separately
+		errorLog = strings.TrimSuffix(errorLog, ".json") + "-" + c.Name + ".json"
+	}
+
 	m := &Manager{
 		Configuration: c,
 		ID:            ID(),
-		Logger: logging.NewLogger(&logging.Config{
-			Name:         "reload",
-			EnableColors: true,
-			PrefixColor:  148,
-		}),
-		Restart:    make(chan bool),
-		cancelFunc: cancelFunc,
-		context:    ctx,
-		gil:        &sync.Once{},
+		ErrorLog:      errorLog,
+		Logger:        logger,
+		Restart:       make(chan bool),
+		cancelFunc:    cancelFunc,
+		context:       ctx,
+		gil:           &sync.Once{},
+		readyCh:       make(chan struct{}),
+		changed:       make(chan struct{}, 1),
 	}
 	return m
 }
 
+func defaultLogger() *slog.Logger {
+	return console.New().Logger()
+}
+
 func (m *Manager) Start() error {
-	w := NewWatcher(m)
-	w.Start()
+	if m.LiveReload != "" {
+		if err := m.serveLiveReload(); err != nil {
+			return err
+		}
+	}
 
-	restart := func() {
-		m.Restart <- true
+	if m.Proxy != "" {
+		if err := m.serveProxy(); err != nil {
+			return err
+		}
 	}
 
-	debounced := use.Debounce(100 * time.Millisecond)
+	w := NewWatcher(m)
+	w.Start()
 
 	if !m.Debug {
-		go func() {
-			for {
-				select {
-				case event := <-w.Events():
-					if !w.isFileEligibleForChange(event.Name) {
-						continue
-					}
-
-					if event.Op != fsnotify.Chmod {
-						debounced(restart)
-					}
-
-					if w.ForcePolling {
-						//w.Logger.Print("Removing file from watchlist: %s", event.Name)
-						w.Remove(event.Name)
-						w.Add(event.Name)
-					}
-
-				case <-m.context.Done():
-					m.Logger.Print("Shutting down")
-					break
-				}
-			}
-		}()
+		go m.watch(w)
 	}
+
 	go func() {
 		for {
 			select {
 			case err := <-w.Errors():
-				m.Logger.Error("Manager error", err)
+				m.Logger.Error("Manager error", "error", err)
 			case <-m.context.Done():
-				break
+				return
 			}
 		}
 	}()
 
-	m.runner()
+	return m.runner()
+}
+
+// ProcessError is returned when the process fails. Output holds what the
+// process wrote to stderr.
+type ProcessError struct {
+	Output string
+	Err    error
+}
+
+func (e *ProcessError) Error() string {
+	return fmt.Sprintf("%s\n%s", e.Err, e.Output)
+}
+
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+// BuildError is returned when the application doesn't compile. Output
+// holds the output of the compiler.
+type BuildError struct {
+	Output string
+	Err    error
+}
+
+func (e *BuildError) Error() string {
+	return fmt.Sprintf("build failed: %s\n%s", e.Err, e.Output)
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// builds reports whether the application is built before it runs, the
+// legacy mode only runs Command.
+func (m *Manager) builds() bool {
+	return m.BinaryName != ""
+}
+
+// restart builds the application and replaces the running process with the
+// new binary. When the build fails the running process is kept. It only
+// returns an error when the application can never be built, e.g. because
+// AppRoot contains no Go files.
+func (m *Manager) restart() error {
+	if err := m.prepare(); err != nil {
+		var buildErr *BuildError
+		if !errors.As(err, &buildErr) {
+			m.Logger.Error("Build failed", "error", err)
+			return nil
+		}
+
+		if nothingToBuild(buildErr.Output) {
+			m.Logger.Error("Build failed", "error", err)
+			return err
+		}
+
+		m.Logger.Error("Build failed, keeping the previous process running", "error", buildErr.Err)
+		fmt.Fprint(m.stderr(), buildErr.Output)
+		m.recordFailure(buildlog.PhaseBuild, buildErr.Err, buildErr.Output)
+		m.releaseRequests()
+		return nil
+	}
+
+	m.clearFailure()
+	m.holdRequests()
+	m.stop()
+
+	if m.context.Err() != nil {
+		// shutting down, the runner doesn't stop another process
+		return nil
+	}
+
+	m.run()
+
+	return nil
+}
+
+// prepare runs BuildCommand, e.g. an asset compiler, and builds the
+// application, when they are configured.
+func (m *Manager) prepare() error {
+	if m.BuildCommand != "" {
+		if err := m.runBuildCommand(); err != nil {
+			return err
+		}
+	}
+
+	if m.builds() {
+		return m.build()
+	}
+
 	return nil
 }
 
-func (m *Manager) build() *exec.Cmd {
+// runBuildCommand runs BuildCommand in AppRoot with CommandEnv.
+func (m *Manager) runBuildCommand() error {
+	started := time.Now()
 
-	timer := use.TimeRecord()
-	//m.Logger.Print("Rebuild on: %s", event.Name)
+	args, err := parseCommandLine(m.BuildCommand)
+	if err != nil {
+		return err
+	}
 
-	command, args := m.getCommandArguments()
-	cmd := exec.CommandContext(m.context, command, args...)
+	if len(args) == 0 {
+		return fmt.Errorf("empty build command %q", m.BuildCommand)
+	}
+
+	cmd := exec.CommandContext(m.context, args[0], args[1:]...)
 	cmd.Dir = m.AppRoot
+	cmd.Env = append(os.Environ(), m.CommandEnv...)
 
-	err := m.runAndListen(cmd)
-	m.cmd = cmd
+	m.Logger.Info("Running build command", "command", m.BuildCommand)
+
+	out, err := cmd.CombinedOutput()
 	if err != nil {
-		if strings.Contains(err.Error(), "no buildable Go source files") {
-			m.cancelFunc()
-			log.Fatal(err)
+		return &BuildError{Output: string(out), Err: err}
+	}
+
+	m.Logger.Info("Build command completed", "elapsed", time.Since(started).Round(time.Millisecond))
+
+	return nil
+}
+
+// nothingToBuild reports whether the compiler found no Go files to build.
+func nothingToBuild(output string) bool {
+	for _, message := range []string{"no buildable Go source files", "no Go files in", "build constraints exclude all Go files"} {
+		if strings.Contains(output, message) {
+			return true
 		}
-		return nil
 	}
 
-	m.Logger.Success("Buildings Completed (PID: %d) %s",
-		cmd.Process.Pid,
-		timer.ElapsedColored(),
-	)
+	return false
+}
+
+// build compiles the application with BuildFlags into FullBuildPath.
+func (m *Manager) build() error {
+	started := time.Now()
+
+	args := append([]string{"build", "-o", m.binaryPath()}, m.BuildFlags...)
+	if m.BuildTargetPath != "" {
+		args = append(args, m.BuildTargetPath)
+	}
+
+	cmd := exec.CommandContext(m.context, "go", args...)
+	cmd.Dir = m.AppRoot
+
+	m.Logger.Info("Building", "command", "go "+strings.Join(args, " "))
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return &BuildError{Output: string(out), Err: err}
+	}
+
+	m.Logger.Info("Build completed", "elapsed", time.Since(started).Round(time.Millisecond))
+
+	return nil
+}
+
+// run starts the built binary with CommandFlags, or Command when nothing
+// is built. The process is stored before run returns, so the next stop
+// finds it; only waiting for it happens in the background.
+func (m *Manager) run() {
+	var cmd *exec.Cmd
+	if m.builds() {
+		cmd = exec.Command(m.binaryPath(), m.CommandFlags...)
+	} else {
+		command, args := m.getCommandArguments()
+		cmd = exec.Command(command, append(args, m.CommandFlags...)...)
+	}
+	cmd.Dir = m.AppRoot
+
+	started := time.Now()
+
+	p, err := m.start(cmd)
+	if err != nil {
+		m.processFailed(err)
+		return
+	}
+
+	go func() {
+		m.awaitReady(p, started)
+
+		if err := m.wait(p); err != nil {
+			m.processFailed(err)
+		}
+	}()
+}
+
+func (m *Manager) processFailed(err error) {
+	m.Logger.Error("Process failed", "error", err)
+
+	var processErr *ProcessError
+	if errors.As(err, &processErr) {
+		m.recordFailure(buildlog.PhaseRun, processErr.Err, processErr.Output)
+	} else {
+		m.recordFailure(buildlog.PhaseRun, err, "")
+	}
+
+	// held requests get the error page
+	m.releaseRequests()
+}
+
+// recordFailure writes the failure to the error log, where the application
+// finds it, e.g. to show it with web.ErrorChecker.
+func (m *Manager) recordFailure(phase string, err error, output string) {
+	dir, _ := filepath.Abs(m.AppRoot)
+
+	failure := buildlog.Failure{Phase: phase, Dir: dir, Error: err.Error(), Output: output}
+	if err := buildlog.Write(m.ErrorLog, failure); err != nil {
+		m.Logger.Warn("Write error log", "path", m.ErrorLog, "error", err)
+	}
+}
+
+func (m *Manager) clearFailure() {
+	if err := buildlog.Clear(m.ErrorLog); err != nil {
+		m.Logger.Warn("Clear error log", "path", m.ErrorLog, "error", err)
+	}
+}
+
+// binaryPath returns the absolute path of the binary, so it can be run
+// from AppRoot.
+func (m *Manager) binaryPath() string {
+	path := m.FullBuildPath()
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	if abs, err := filepath.Abs(filepath.Join(m.AppRoot, path)); err == nil {
+		return abs
+	}
+
+	return path
+}
+
+func (m *Manager) stderr() io.Writer {
+	if m.Stderr != nil {
+		return m.Stderr
+	}
 
-	return cmd
+	return os.Stderr
 }
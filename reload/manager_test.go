@@ -0,0 +1,166 @@
+package reload
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evolidev/console/reload/buildlog"
+	"github.com/stretchr/testify/assert"
+)
+
+// tinyMain prints its version when asked, otherwise it runs until it's
+// stopped.
+const tinyMain = `package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+var version = "dev"
+
+func main() {
+	if len(os.Args) > 1 {
+		fmt.Println(version)
+		return
+	}
+
+	time.Sleep(time.Hour)
+}
+`
+
+func TestBuild(t *testing.T) {
+	dir := tinyApp(t, tinyMain)
+
+	m := appManager(t, &Configuration{
+		AppRoot:    dir,
+		BinaryName: "app",
+		BuildPath:  "bin",
+		BuildFlags: []string{"-ldflags", "-X main.version=v1.2"},
+	})
+
+	assert.Nil(t, m.build())
+	assert.Equal(t, filepath.Join(dir, "bin", "app"), m.binaryPath())
+
+	out, err := exec.Command(m.binaryPath(), "version").Output()
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.2\n", string(out))
+}
+
+func TestRestartKeepsProcessWhenBuildFails(t *testing.T) {
+	dir := tinyApp(t, tinyMain)
+
+	m := appManager(t, &Configuration{AppRoot: dir, BinaryName: "app", BuildPath: "bin"})
+	defer m.stop()
+
+	assert.Nil(t, m.restart())
+	first := m.process
+	assert.NotNil(t, first)
+
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() { undefined() }\n")
+
+	assert.Nil(t, m.restart())
+	assert.Same(t, first, m.process, "a failed build keeps the process")
+	assert.False(t, exited(first))
+
+	failure, err := buildlog.Read(m.ErrorLog)
+	assert.Nil(t, err)
+	if assert.NotNil(t, failure) {
+		assert.Equal(t, buildlog.PhaseBuild, failure.Phase)
+		assert.Contains(t, failure.Output, "undefined: undefined")
+	}
+
+	writeFile(t, dir, "main.go", tinyMain)
+
+	assert.Nil(t, m.restart())
+	assert.NotSame(t, first, m.process)
+	assert.True(t, exited(first), "the old process is stopped after the build")
+	assert.False(t, exited(m.process))
+
+	failure, _ = buildlog.Read(m.ErrorLog)
+	assert.Nil(t, failure)
+}
+
+func TestRestartWithoutGoFiles(t *testing.T) {
+	dir := tinyApp(t, "//go:build ignore\n\npackage main\n")
+
+	m := appManager(t, &Configuration{AppRoot: dir, BinaryName: "app", BuildPath: "bin"})
+
+	var buildErr *BuildError
+	assert.ErrorAs(t, m.runner(), &buildErr)
+	assert.Nil(t, m.process)
+	assert.NotNil(t, m.context.Err(), "the manager is stopped")
+}
+
+func TestPrepareRunsBuildCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	m := buildManager(dir, `sh -c "echo $STAGE > assets.txt"`)
+	m.CommandEnv = []string{"STAGE=local"}
+
+	assert.Nil(t, m.prepare())
+
+	out, err := os.ReadFile(filepath.Join(dir, "assets.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "local\n", string(out))
+}
+
+func TestPrepareReportsBuildCommandFailure(t *testing.T) {
+	m := buildManager(t.TempDir(), `sh -c "echo broken stylesheet; exit 2"`)
+	m.BinaryName = "server"
+
+	var buildErr *BuildError
+	assert.ErrorAs(t, m.prepare(), &buildErr)
+	assert.Equal(t, "broken stylesheet\n", buildErr.Output)
+
+	m.BuildCommand = `sh -c "unclosed`
+	assert.NotNil(t, m.prepare())
+}
+
+func buildManager(dir string, command string) *Manager {
+	return &Manager{
+		Configuration: &Configuration{AppRoot: dir, BuildCommand: command},
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		context:       context.Background(),
+	}
+}
+
+// tinyApp writes a main package into a temporary module.
+func tinyApp(t *testing.T, main string) string {
+	t.Setenv("GOWORK", "off")
+
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module tiny\n\ngo 1.21\n")
+	writeFile(t, dir, "main.go", main)
+
+	return dir
+}
+
+func appManager(t *testing.T, c *Configuration) *Manager {
+	c.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	c.Stdout, c.Stderr = io.Discard, io.Discard
+
+	m := NewWithContext(c, context.Background())
+	t.Cleanup(func() {
+		m.cancelFunc()
+		buildlog.Clear(m.ErrorLog)
+	})
+
+	return m
+}
+
+func exited(p *process) bool {
+	select {
+	case <-p.done:
+		return true
+	case <-time.After(100 * time.Millisecond):
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package reload
+
+import (
+	"bytes"
+	"os/exec"
+	"time"
+)
+
+// DefaultStopTimeout is the grace period a process gets to exit after the
+// stop signal before it is killed.
+const DefaultStopTimeout = 5 * time.Second
+
+// process is a child started by the manager. Its children run in the same
+// process group, so they are stopped together.
+type process struct {
+	cmd      *exec.Cmd
+	done     chan struct{}
+	err      error
+	stderr   bytes.Buffer
+	logReady chan struct{}
+	stopped  bool
+}
+
+func (m *Manager) setProcess(p *process) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.process = p
+}
+
+// stop stops the running process: the stop signal is sent to its process
+// group and the group is killed when it is still running after the grace
+// period. Children which outlive the process are killed as well, so they
+// don't keep ports bound.
+func (m *Manager) stop() {
+	m.mu.Lock()
+	p := m.process
+	m.process = nil
+	if p != nil {
+		p.stopped = true
+	}
+	m.mu.Unlock()
+
+	if p == nil {
+		m.Logger.Info("No process running")
+		return
+	}
+
+	pid := p.cmd.Process.Pid
+	defer killOrphans(p.cmd)
+
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	sig := m.stopSignal()
+	m.Logger.Info("Stopping process", "pid", pid, "signal", sig)
+
+	if err := signalGroup(p.cmd, sig); err != nil {
+		m.Logger.Debug("Signal process", "pid", pid, "error", err)
+	}
+
+	timeout := m.StopTimeout
+	if timeout <= 0 {
+		timeout = DefaultStopTimeout
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-p.done:
+	case <-timer.C:
+		m.Logger.Warn("Process did not stop in time, killing it", "pid", pid, "timeout", timeout)
+		killGroup(p.cmd)
+		<-p.done
+	}
+}
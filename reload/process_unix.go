@@ -0,0 +1,59 @@
+//go:build !windows
+
+package reload
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+var signals = map[string]syscall.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// setProcessGroup starts the command in a new process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// stopSignal returns the configured stop signal, SIGTERM by default.
+func (m *Manager) stopSignal() os.Signal {
+	name := strings.ToUpper(strings.TrimSpace(m.StopSignal))
+	if name == "" {
+		return syscall.SIGTERM
+	}
+
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	if sig, ok := signals[name]; ok {
+		return sig
+	}
+
+	m.Logger.Warn("Unknown stop signal, using SIGTERM", "signal", m.StopSignal)
+
+	return syscall.SIGTERM
+}
+
+func signalGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return syscall.Kill(-cmd.Process.Pid, sig.(syscall.Signal))
+}
+
+func killGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// killOrphans kills children which are still running in the process group
+// after the process exited.
+func killOrphans(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,73 @@
+//go:build !windows
+
+package reload
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopProcessGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal string
+		script string
+		killed bool
+	}{
+		{"stops on the signal", "", `sleep 60 & echo $! > child; wait`, false},
+		{"stops on the configured signal", "SIGUSR1", `trap "" TERM; sleep 60 & echo $! > child; wait`, false},
+		{"killed after the timeout", "", `trap "" TERM; sleep 60 & echo $! > child; wait`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			m := appManager(t, &Configuration{
+				AppRoot:     dir,
+				Command:     "sh -c '" + test.script + "'",
+				StopSignal:  test.signal,
+				StopTimeout: 300 * time.Millisecond,
+			})
+
+			m.run()
+			p := m.process
+
+			var child int
+			assert.Eventually(t, func() bool {
+				data, _ := os.ReadFile(filepath.Join(dir, "child"))
+				child, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+				return child > 0
+			}, 5*time.Second, 10*time.Millisecond)
+
+			started := time.Now()
+			m.stop()
+			elapsed := time.Since(started)
+
+			assert.False(t, running(p.cmd.Process.Pid), "the process is gone")
+			assert.Eventually(t, func() bool { return !running(child) }, time.Second, 10*time.Millisecond, "the child is gone")
+
+			if test.killed {
+				assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond, "the grace period is awaited")
+			} else {
+				assert.Less(t, elapsed, 300*time.Millisecond)
+			}
+		})
+	}
+}
+
+// running reports whether pid is running, zombies which aren't reaped yet
+// count as gone.
+func running(pid int) bool {
+	out, err := exec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+	state := strings.TrimSpace(string(out))
+
+	return err == nil && state != "" && !strings.HasPrefix(state, "Z")
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package reload
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// stopSignal returns os.Kill, windows cannot send signals to other
+// processes.
+func (m *Manager) stopSignal() os.Signal {
+	return os.Kill
+}
+
+func signalGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return killGroup(cmd)
+}
+
+// killGroup kills the process and all of its children.
+func killGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// killOrphans does nothing, the children of an exited process can't be
+// found by its PID anymore.
+func killOrphans(cmd *exec.Cmd) {}
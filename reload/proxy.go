@@ -0,0 +1,71 @@
+package reload
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/evolidev/console/reload/web"
+)
+
+// serveProxy starts the proxy which forwards to the application and holds
+// requests while it restarts.
+func (m *Manager) serveProxy() error {
+	target, err := proxyTarget(m.ProxyTarget)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", m.Proxy)
+	if err != nil {
+		return err
+	}
+
+	m.proxy = web.NewProxy(target, m.ErrorLog)
+	m.proxy.Timeout = m.ProxyTimeout
+	m.proxy.LiveReloadURL = m.liveReloadURL
+
+	// requests are released once the application accepts connections
+	if !m.Ready.enabled() {
+		m.Ready.TCP = target.Host
+	}
+
+	server := &http.Server{Handler: m.proxy}
+	go server.Serve(listener)
+	go func() {
+		<-m.context.Done()
+		server.Close()
+	}()
+
+	m.Logger.Info("Proxy", "url", "http://"+browserAddress(listener.Addr().(*net.TCPAddr)), "target", target.String())
+
+	return nil
+}
+
+// proxyTarget parses the address of the application, a bare host:port is
+// reached with http.
+func proxyTarget(address string) (*url.URL, error) {
+	if address == "" {
+		return nil, errors.New("proxy_target is required to run the proxy")
+	}
+
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	return url.Parse(address)
+}
+
+func (m *Manager) holdRequests() {
+	if m.proxy != nil {
+		m.proxy.Hold()
+	}
+}
+
+func (m *Manager) releaseRequests() {
+	if m.proxy != nil {
+		m.proxy.Release()
+	}
+}
@@ -0,0 +1,225 @@
+package reload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DefaultReadyTimeout is how long a process may take to become ready.
+const DefaultReadyTimeout = 30 * time.Second
+
+var errExited = errors.New("process exited during startup")
+
+// ReadyCheck decides when a restarted process is ready: when TCP accepts
+// connections, HTTP answers with 2xx or a line of stdout matches Log. A
+// process without a check is ready once it started.
+type ReadyCheck struct {
+	TCP     string        `yaml:"tcp"`
+	HTTP    string        `yaml:"http"`
+	Log     string        `yaml:"log"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (r ReadyCheck) enabled() bool {
+	return r.TCP != "" || r.HTTP != "" || r.Log != ""
+}
+
+// awaitReady reports when the process is ready and reloads the pages
+// connected to live reload.
+func (m *Manager) awaitReady(p *process, started time.Time) {
+	err := m.checkReady(p)
+	if errors.Is(err, errExited) {
+		m.mu.Lock()
+		stopped := p.stopped
+		m.mu.Unlock()
+
+		// the output of the process is reported when it is waited for
+		if !stopped {
+			m.Logger.Error("Process crashed during startup", "pid", p.cmd.Process.Pid)
+		}
+		return
+	}
+
+	if err != nil {
+		m.Logger.Warn("Process is not ready", "pid", p.cmd.Process.Pid, "error", err)
+		m.ready()
+		return
+	}
+
+	m.Logger.Info("Ready in "+formatElapsed(time.Since(started)), "pid", p.cmd.Process.Pid)
+	m.ready()
+
+	if m.liveReload != nil {
+		m.liveReload.Reload()
+	}
+}
+
+// ready releases what waits for the process: the requests held by the
+// proxy, the processes which depend on it and, after a restart, restarts
+// them as well.
+func (m *Manager) ready() {
+	m.releaseRequests()
+
+	first := false
+	m.readyOnce.Do(func() {
+		close(m.readyCh)
+		first = true
+	})
+
+	if first {
+		return
+	}
+
+	for _, dependent := range m.dependents {
+		go dependent.requestRestart()
+	}
+}
+
+// awaitDependencies waits until the processes m depends on are ready, it
+// returns false when the manager stopped meanwhile.
+func (m *Manager) awaitDependencies() bool {
+	for _, dependency := range m.dependsOn {
+		select {
+		case <-dependency.readyCh:
+			continue
+		default:
+		}
+
+		m.Logger.Info("Waiting for dependency", "dependency", dependency.Name)
+
+		select {
+		case <-dependency.readyCh:
+		case <-m.context.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *Manager) checkReady(p *process) error {
+	if !m.Ready.enabled() {
+		return nil
+	}
+
+	timeout := m.Ready.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(m.context, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	// the port and the URL are only probed once the log line matched, which
+	// may come before the port is open
+	logReady := p.logReady
+	logged := m.Ready.Log == ""
+
+	for {
+		if logged && m.probe(ctx) {
+			return nil
+		}
+
+		select {
+		case <-p.done:
+			return errExited
+		case <-logReady:
+			// the closed channel would be ready in every round
+			logReady = nil
+			logged = true
+			continue
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe checks the port and the URL once.
+func (m *Manager) probe(ctx context.Context) bool {
+	if m.Ready.TCP != "" {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", m.Ready.TCP)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+
+	if m.Ready.HTTP != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.Ready.HTTP, nil)
+		if err != nil {
+			return false
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+
+		return res.StatusCode >= 200 && res.StatusCode < 300
+	}
+
+	return true
+}
+
+// lineMatcher closes matched once a line written to it matches.
+type lineMatcher struct {
+	pattern *regexp.Regexp
+	matched chan struct{}
+	once    sync.Once
+	line    []byte
+}
+
+func newLineMatcher(pattern string) (*lineMatcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ready log pattern: %w", err)
+	}
+
+	return &lineMatcher{pattern: re, matched: make(chan struct{})}, nil
+}
+
+func (l *lineMatcher) Write(b []byte) (int, error) {
+	select {
+	case <-l.matched:
+		return len(b), nil
+	default:
+	}
+
+	l.line = append(l.line, b...)
+	for {
+		i := bytes.IndexByte(l.line, '\n')
+		if i < 0 {
+			break
+		}
+
+		if l.pattern.Match(l.line[:i]) {
+			l.once.Do(func() { close(l.matched) })
+			l.line = nil
+			break
+		}
+		l.line = l.line[i+1:]
+	}
+
+	return len(b), nil
+}
+
+func formatElapsed(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,126 @@
+package reload
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineMatcher(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   bool
+	}{
+		{"whole line", []string{"server listening on :8080\n"}, true},
+		{"split line", []string{"server list", "ening on :8080\n"}, true},
+		{"later line", []string{"starting\nconnecting\n", "listening\n"}, true},
+		{"unfinished line", []string{"listening"}, false},
+		{"other lines", []string{"starting\n", "stopped\n"}, false},
+	}
+
+	for _, test := range tests {
+		matcher, err := newLineMatcher("listening")
+		assert.Nil(t, err)
+
+		for _, w := range test.writes {
+			n, err := matcher.Write([]byte(w))
+			assert.Nil(t, err)
+			assert.Equal(t, len(w), n)
+		}
+
+		select {
+		case <-matcher.matched:
+			assert.True(t, test.want, test.name)
+		default:
+			assert.False(t, test.want, test.name)
+		}
+	}
+
+	_, err := newLineMatcher("(")
+	assert.NotNil(t, err)
+}
+
+func TestCheckReadyHTTP(t *testing.T) {
+	var requests atomic.Int32
+	app := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if requests.Add(1) < 3 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer app.Close()
+
+	m := readyManager(ReadyCheck{HTTP: app.URL})
+	assert.Nil(t, m.checkReady(&process{done: make(chan struct{})}))
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestCheckReadyLogBeforePort(t *testing.T) {
+	var requests atomic.Int32
+	opened := time.Now().Add(300 * time.Millisecond)
+	app := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		if time.Now().Before(opened) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer app.Close()
+
+	logReady := make(chan struct{})
+	close(logReady)
+
+	m := readyManager(ReadyCheck{HTTP: app.URL, Log: "listening"})
+	assert.Nil(t, m.checkReady(&process{done: make(chan struct{}), logReady: logReady}))
+
+	// one probe per tick, not a busy loop
+	assert.LessOrEqual(t, requests.Load(), int32(6))
+}
+
+func TestCheckReadyWaitsForLog(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	logReady := make(chan struct{})
+	time.AfterFunc(150*time.Millisecond, func() { close(logReady) })
+
+	m := readyManager(ReadyCheck{TCP: listener.Addr().String(), Log: "listening"})
+
+	started := time.Now()
+	assert.Nil(t, m.checkReady(&process{done: make(chan struct{}), logReady: logReady}))
+	assert.GreaterOrEqual(t, time.Since(started), 150*time.Millisecond)
+}
+
+func TestCheckReadyExitedAndTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	done := make(chan struct{})
+	close(done)
+
+	m := readyManager(ReadyCheck{TCP: addr})
+	assert.ErrorIs(t, m.checkReady(&process{done: done}), errExited)
+
+	m.Ready.Timeout = 150 * time.Millisecond
+	assert.EqualError(t, m.checkReady(&process{done: make(chan struct{})}), "not ready after 150ms")
+
+	m.Ready = ReadyCheck{}
+	assert.Nil(t, m.checkReady(&process{done: make(chan struct{})}))
+}
+
+func TestFormatElapsed(t *testing.T) {
+	assert.Equal(t, "302ms", formatElapsed(302400*time.Microsecond))
+	assert.Equal(t, "1.3s", formatElapsed(1270*time.Millisecond))
+}
+
+func readyManager(ready ReadyCheck) *Manager {
+	return &Manager{Configuration: &Configuration{Ready: ready}, context: context.Background()}
+}
@@ -13,7 +13,6 @@ func Init() {
 
 // ErrConfigNotExist is returned when a configuration file cannot be found.
 var ErrConfigNotExist = errors.New("no config file was found")
-var debug = true
 
 func RunBackground(config *Configuration) error {
 	r := NewWithContext(config, context.Background())
@@ -26,22 +25,27 @@ func Run(cfgFile string) error {
 }
 
 func RunWithContext(cfgFile string, ctx context.Context) error {
-	c := &Configuration{}
+	c := &Configuration{Logger: defaultLogger()}
 
 	if err := loadConfig(c, cfgFile); err != nil {
 		if err != ErrConfigNotExist {
 			return err
 		}
 
-		log.Println("No configuration loaded, proceeding with defaults")
+		c.Logger.Info("No configuration loaded, proceeding with defaults")
 	}
 
 	if len(c.Path) > 0 {
-		log.Printf("Configuration loaded from %s\n", c.Path)
+		c.Logger.Info("Configuration loaded", "path", c.Path)
 	}
 
-	if debug {
-		c.Debug = true
+	if len(c.Processes) > 0 {
+		g, err := NewGroup(c, ctx)
+		if err != nil {
+			return err
+		}
+
+		return g.Start()
 	}
 
 	r := NewWithContext(c, ctx)
@@ -1,39 +1,56 @@
 package reload
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"github.com/evolidev/filesystem"
-	"github.com/evolidev/use"
+	"github.com/evolidev/console/reload/buildlog"
+	"github.com/evolidev/console/reload/web"
 	"io"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
-	"syscall"
 )
 
-func (m *Manager) runner() {
+// runner serializes the restarts of the process until the context is done,
+// or the application can't be built at all.
+func (m *Manager) runner() error {
+	if !m.awaitDependencies() {
+		return nil
+	}
 
-	go m.build()
+	if err := m.restart(); err != nil {
+		m.cancelFunc()
+		return err
+	}
 
 	for {
-		<-m.Restart
-		m.Logger.Log("Restarting...")
-
-		pid := filesystem.Read(use.StoragePath("tmp/serve.pid"))
-		if pid != "" {
-			m.Logger.Log("Killing process with PID: %s", pid)
-			// convert pid to int
-			pidInt, _ := strconv.Atoi(pid)
-			syscall.Kill(pidInt, syscall.SIGTERM)
-
-		} else {
-			m.Logger.Print("No process running")
+		select {
+		case <-m.Restart:
+			m.Logger.Info("Restarting...")
+		case <-m.changed:
+			batch := m.takePending()
+			if !m.runHooks(batch) {
+				m.Logger.Info(batch.String(), "restart", "skipped by a hook")
+				continue
+			}
+			m.Logger.Info(batch.String())
+		case <-m.context.Done():
+			m.stop()
+			return nil
+		}
+
+		if err := m.restart(); err != nil {
+			m.cancelFunc()
+			m.stop()
+			return err
 		}
+	}
+}
 
-		go m.build()
+func (m *Manager) requestRestart() {
+	select {
+	case m.Restart <- true:
+	case <-m.context.Done():
 	}
 }
 
@@ -42,7 +59,7 @@ func (m *Manager) getCommandArguments() (string, []string) {
 	parsed, err := parseCommandLine(m.Command)
 
 	if err != nil {
-		m.Logger.Error("Failed to start command:", m.Command)
+		m.Logger.Error("Failed to start command", "command", m.Command)
 		panic(err)
 	}
 
@@ -56,7 +73,8 @@ func (m *Manager) getCommand() *exec.Cmd {
 	return exec.Command(command, args...)
 }
 
-func (m *Manager) runAndListen(cmd *exec.Cmd) error {
+// start starts the process with the configured environment and output.
+func (m *Manager) start(cmd *exec.Cmd) (*process, error) {
 	cmd.Stderr = m.Stderr
 	if cmd.Stderr == nil {
 		cmd.Stderr = os.Stderr
@@ -72,24 +90,55 @@ func (m *Manager) runAndListen(cmd *exec.Cmd) error {
 		cmd.Stdout = os.Stdout
 	}
 
-	var stderr bytes.Buffer
+	p := &process{cmd: cmd, done: make(chan struct{})}
+
+	cmd.Stderr = io.MultiWriter(&p.stderr, cmd.Stderr)
+
+	if m.Ready.Log != "" {
+		matcher, err := newLineMatcher(m.Ready.Log)
+		if err != nil {
+			return nil, err
+		}
 
-	cmd.Stderr = io.MultiWriter(&stderr, cmd.Stderr)
+		p.logReady = matcher.matched
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, matcher)
+	}
 
 	// Set the environment variables from config
-	if len(m.CommandEnv) != 0 {
-		cmd.Env = append(m.CommandEnv, os.Environ()...)
+	cmd.Env = append(os.Environ(), m.CommandEnv...)
+	cmd.Env = append(cmd.Env, buildlog.Env+"="+m.ErrorLog)
+	if m.liveReload != nil {
+		cmd.Env = append(cmd.Env, web.EnvLiveReload+"="+m.liveReloadURL)
 	}
 
-	err := cmd.Start()
-	if err != nil {
-		return fmt.Errorf("%s\n%s", err, stderr.String())
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, &ProcessError{Err: err, Output: p.stderr.String()}
 	}
 
-	m.Logger.Success("Running: %s (PID: %d)", strings.Join(cmd.Args, " "), cmd.Process.Pid)
-	err = cmd.Wait()
-	if err != nil {
-		return fmt.Errorf("%s\n%s", err, stderr.String())
+	m.setProcess(p)
+	m.Logger.Info("Running", "command", strings.Join(cmd.Args, " "), "pid", cmd.Process.Pid)
+
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+
+	return p, nil
+}
+
+// wait waits until the process exits. A process which was stopped by the
+// manager didn't fail.
+func (m *Manager) wait(p *process) error {
+	<-p.done
+
+	m.mu.Lock()
+	stopped := p.stopped
+	m.mu.Unlock()
+
+	if p.err != nil && !stopped {
+		return &ProcessError{Err: p.err, Output: p.stderr.String()}
 	}
 	return nil
 }
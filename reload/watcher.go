@@ -7,76 +7,247 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/evolidev/console/filenotify"
+	"github.com/evolidev/console/reload/glob"
+	"github.com/fsnotify/fsnotify"
 )
 
 type Watcher struct {
 	filenotify.FileWatcher
 	*Manager
 	context context.Context
+	exclude []glob.Pattern
+	include []glob.Pattern
+	mu      sync.RWMutex
+	polling bool
+	dirs    map[string]struct{}
 }
 
 func NewWatcher(r *Manager) *Watcher {
-	var watcher filenotify.FileWatcher
+	w := &Watcher{
+		Manager: r,
+		context: r.context,
+		polling: r.ForcePolling,
+		dirs:    make(map[string]struct{}),
+	}
 
-	if r.ForcePolling {
-		watcher = filenotify.NewPollingWatcher()
+	if w.polling {
+		w.FileWatcher = filenotify.NewPollingWatcher()
+	} else if watcher, err := filenotify.NewEventWatcher(); err == nil {
+		w.FileWatcher = watcher
 	} else {
-		watcher, _ = filenotify.NewEventWatcher()
+		r.Logger.Warn("File events are not available, falling back to polling", "error", err)
+		w.FileWatcher = filenotify.NewPollingWatcher()
+		w.polling = true
 	}
 
-	return &Watcher{
-		FileWatcher: watcher,
-		Manager:     r,
-		context:     r.context,
-	}
+	w.loadRules()
+
+	return w
 }
 
 func (w *Watcher) Start() {
-	if w.ForcePolling {
+	if w.isPolling() {
 		w.watchWithPolling()
 	} else {
 		w.watchWithFsNotify()
 	}
 }
 
+// Events returns the events of the current watcher. It is replaced by a
+// poller when the watch limit is reached, so it's only used under the lock.
+func (w *Watcher) Events() <-chan fsnotify.Event {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.FileWatcher.Events()
+}
+
+func (w *Watcher) Errors() <-chan error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.FileWatcher.Errors()
+}
+
+func (w *Watcher) Add(name string) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.FileWatcher.Add(name)
+}
+
+func (w *Watcher) Remove(name string) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.FileWatcher.Remove(name)
+}
+
+func (w *Watcher) isPolling() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.polling
+}
+
+// roots returns the directories which are watched: Watch relative to
+// AppRoot, or AppRoot itself.
+func (w *Watcher) roots() []string {
+	if len(w.Watch) == 0 {
+		return []string{w.AppRoot}
+	}
+
+	roots := make([]string, 0, len(w.Watch))
+	for _, path := range w.Watch {
+		root := filepath.Join(w.AppRoot, path)
+		if _, err := os.Stat(root); err != nil {
+			w.Logger.Debug("Skip watch path", "path", root, "error", err)
+			continue
+		}
+
+		roots = append(roots, root)
+	}
+
+	return roots
+}
+
 func (w *Watcher) watchWithFsNotify() {
-	watchDir := w.AppRoot
+	for _, root := range w.roots() {
+		if _, err := w.watchDir(root); err != nil {
+			w.fallBack(err)
+			return
+		}
+	}
+}
+
+// watchDir watches dir and its subdirectories which aren't excluded. It
+// returns the watched files in them, which exist when a package was copied
+// or checked out.
+func (w *Watcher) watchDir(dir string) ([]string, error) {
+	var found []string
 
-	if err := filepath.WalkDir(watchDir, func(path string, d fs.DirEntry, err error) error {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return err
+			// e.g. removed again in the meantime
+			w.Logger.Debug("Watch FS", "path", path, "error", err)
+			return nil
+		}
+
+		if !d.IsDir() {
+			if w.isWatchedFile(path) {
+				found = append(found, path)
+			}
+			return nil
+		}
+
+		if path != dir && w.isIgnoredFolder(path) {
+			return filepath.SkipDir
 		}
 
-		if d.IsDir() {
-			return w.FileWatcher.Add(path)
+		if err := w.Add(path); err != nil {
+			return err
 		}
 
+		w.mu.Lock()
+		w.dirs[filepath.Clean(path)] = struct{}{}
+		w.mu.Unlock()
+
+		return nil
+	})
+
+	return found, err
+}
+
+// trackDirs keeps the directory watches in sync in fsnotify mode: created
+// directories are watched, removed ones are forgotten. It returns the
+// watched files in a created directory.
+func (w *Watcher) trackDirs(event fsnotify.Event) []string {
+	if event.Name == "" || w.isPolling() {
 		return nil
-	}); err != nil {
-		w.Logger.Error("Watch FS", err)
 	}
+
+	path := filepath.Clean(event.Name)
+
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		w.forgetDir(path)
+		return nil
+	}
+
+	if !event.Has(fsnotify.Create) {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() || w.isIgnoredFolder(path) {
+		return nil
+	}
+
+	w.Logger.Debug("Watch new directory", "path", path)
+
+	found, err := w.watchDir(path)
+	if err != nil {
+		w.fallBack(err)
+	}
+
+	return found
+}
+
+func (w *Watcher) forgetDir(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prefix := path + string(filepath.Separator)
+	for dir := range w.dirs {
+		if dir == path || strings.HasPrefix(dir, prefix) {
+			// the watch of a removed directory is already gone
+			_ = w.FileWatcher.Remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+}
+
+// fallBack switches to polling when no more directories can be watched,
+// e.g. because fs.inotify.max_user_watches is reached.
+func (w *Watcher) fallBack(err error) {
+	if !isWatchLimit(err) {
+		w.Logger.Error("Watch FS", "error", err)
+		return
+	}
+
+	w.Logger.Warn("Watch limit reached, falling back to polling; raise fs.inotify.max_user_watches to use file events", "error", err)
+
+	w.mu.Lock()
+	watcher := w.FileWatcher
+	w.FileWatcher = filenotify.NewPollingWatcher()
+	w.polling = true
+	w.dirs = make(map[string]struct{})
+	w.mu.Unlock()
+
+	watcher.Close()
+	w.watchWithPolling()
+}
+
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
 }
 
 func (w *Watcher) watchWithPolling() {
 	go func() {
 		for {
-			err := filepath.Walk(w.AppRoot, func(path string, info os.FileInfo, err error) error {
+			err := w.walkRoots(func(path string, info os.FileInfo, err error) error {
 				//w.Logger.Print(fmt.Sprintf("Check file: %s", path))
 
 				if info == nil {
 					w.cancelFunc()
 					return errors.New("nil directory")
 				}
-				if info.IsDir() {
-					if strings.HasPrefix(filepath.Base(path), "_") {
-						return filepath.SkipDir
-					}
-					if len(path) > 1 && strings.HasPrefix(filepath.Base(path), ".") || w.isIgnoredFolder(path) {
-						return filepath.SkipDir
-					}
+				if info.IsDir() && w.isIgnoredFolder(path) {
+					return filepath.SkipDir
 				}
 				if w.isWatchedFile(path) {
 					//w.Logger.Print(fmt.Sprintf("Add file: %s", path))
@@ -95,30 +266,22 @@ func (w *Watcher) watchWithPolling() {
 	}()
 }
 
-func (w *Watcher) isIgnoredFolder(path string) bool {
-	paths := strings.Split(path, "/")
-	if len(paths) <= 0 {
-		return false
-	}
-
-	for _, e := range w.IgnoredFolders {
-		if strings.TrimSpace(e) == paths[0] {
-			return true
+func (w *Watcher) walkRoots(fn filepath.WalkFunc) error {
+	for _, root := range w.roots() {
+		if err := filepath.Walk(root, fn); err != nil {
+			return err
 		}
 	}
-	return false
-}
 
-func (w *Watcher) isWatchedFile(path string) bool {
-	ext := filepath.Ext(path)
+	return nil
+}
 
-	for _, e := range w.IncludedExtensions {
-		if strings.TrimSpace(e) == ext {
-			return true
-		}
-	}
+func (w *Watcher) isIgnoredFolder(path string) bool {
+	return !w.decide(path, true).Watched
+}
 
-	return false
+func (w *Watcher) isWatchedFile(path string) bool {
+	return w.decide(path, false).Watched
 }
 
 func (w *Watcher) isFileEligibleForChange(path string) bool {
@@ -130,24 +293,18 @@ func (w *Watcher) isFileEligibleForChange(path string) bool {
 
 	info, err := os.Stat(path)
 	if err != nil {
-		w.Logger.Error("Is file eligible: %s", err)
+		w.Logger.Debug("Is file eligible", "error", err)
 		return false
 	}
 
 	if info == nil {
 		//w.cancelFunc() //??
-		w.Logger.Error("info not found")
+		w.Logger.Error("Info not found", "path", path)
 		return false
 	}
 
-	if info.IsDir() {
-		basePath := filepath.Base(path)
-		if strings.HasPrefix(basePath, "_") {
-			return false
-		}
-		if len(path) > 1 && strings.HasPrefix(basePath, ".") || w.isIgnoredFolder(path) {
-			return false
-		}
+	if info.IsDir() && w.isIgnoredFolder(path) {
+		return false
 	}
 
 	if !w.isWatchedFile(path) {
@@ -0,0 +1,119 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackDirs(t *testing.T) {
+	dir := t.TempDir()
+	mkdirs(t, dir, "api/v1", "node_modules/lib", ".git/objects")
+
+	w := testWatcher(t, &Configuration{AppRoot: dir, IgnoredFolders: []string{"node_modules"}})
+
+	found, err := w.watchDir(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, found)
+	assert.Equal(t, []string{".", "api", "api/v1"}, watchedDirs(w))
+
+	mkdirs(t, dir, "shop/cart", "shopping", "node_modules/other")
+	writeFile(t, dir, "shop/cart/cart.go", "package cart")
+	writeFile(t, dir, "shop/README.md", "# shop")
+
+	tests := []struct {
+		name  string
+		event fsnotify.Event
+		files []string
+		dirs  []string
+	}{
+		{"created package", event(dir, "shop", fsnotify.Create), []string{"shop/cart/cart.go"}, []string{".", "api", "api/v1", "shop", "shop/cart"}},
+		{"created directory without files", event(dir, "shopping", fsnotify.Create), nil, []string{".", "api", "api/v1", "shop", "shop/cart", "shopping"}},
+		{"created file", event(dir, "shop/cart/cart.go", fsnotify.Create), nil, []string{".", "api", "api/v1", "shop", "shop/cart", "shopping"}},
+		{"ignored directory", event(dir, "node_modules/other", fsnotify.Create), nil, []string{".", "api", "api/v1", "shop", "shop/cart", "shopping"}},
+		{"removed directory", event(dir, "shop", fsnotify.Remove), nil, []string{".", "api", "api/v1", "shopping"}},
+		{"renamed directory", event(dir, "api", fsnotify.Rename), nil, []string{".", "shopping"}},
+		{"unknown directory", event(dir, "missing", fsnotify.Remove), nil, []string{".", "shopping"}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.files, relative(dir, w.trackDirs(test.event)), test.name)
+		assert.Equal(t, test.dirs, watchedDirs(w), test.name)
+	}
+}
+
+func TestTrackDirsWhilePolling(t *testing.T) {
+	dir := t.TempDir()
+	mkdirs(t, dir, "api")
+	writeFile(t, dir, "api/main.go", "package main")
+
+	w := testWatcher(t, &Configuration{AppRoot: dir, ForcePolling: true})
+
+	assert.Empty(t, w.trackDirs(event(dir, "api", fsnotify.Create)))
+	assert.Empty(t, watchedDirs(w))
+}
+
+func TestIsWatchLimit(t *testing.T) {
+	assert.True(t, isWatchLimit(&os.PathError{Op: "add", Path: "api", Err: syscall.ENOSPC}))
+	assert.True(t, isWatchLimit(fmt.Errorf("watch: %w", syscall.EMFILE)))
+	assert.False(t, isWatchLimit(os.ErrNotExist))
+}
+
+func testWatcher(t *testing.T, c *Configuration) *Watcher {
+	c.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := NewWatcher(NewWithContext(c, ctx))
+
+	t.Cleanup(func() {
+		cancel()
+		w.Close()
+	})
+
+	return w
+}
+
+func mkdirs(t *testing.T, root string, dirs ...string) {
+	for _, dir := range dirs {
+		assert.Nil(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+}
+
+func event(root string, path string, op fsnotify.Op) fsnotify.Event {
+	return fsnotify.Event{Name: filepath.Join(root, path), Op: op}
+}
+
+// watchedDirs returns the watched directories relative to AppRoot.
+func watchedDirs(w *Watcher) []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var dirs []string
+	for dir := range w.dirs {
+		rel, _ := filepath.Rel(w.AppRoot, dir)
+		dirs = append(dirs, filepath.ToSlash(rel))
+	}
+	sort.Strings(dirs)
+
+	return dirs
+}
+
+// relative returns paths relative to root with forward slashes.
+func relative(root string, paths []string) []string {
+	var rel []string
+	for _, path := range paths {
+		r, _ := filepath.Rel(root, path)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+
+	return rel
+}
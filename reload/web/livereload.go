@@ -0,0 +1,285 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvLiveReload is the environment variable the manager passes the URL of
+// its live reload server with.
+const EnvLiveReload = "RELOAD_LIVERELOAD_URL"
+
+// LiveReloadURL is the live reload server the injected script connects to.
+// Without one LiveReloadScript doesn't inject anything.
+var LiveReloadURL = os.Getenv(EnvLiveReload)
+
+// KeepAlive is how often a comment is sent to idle clients, so proxies
+// don't close the connection.
+var KeepAlive = 15 * time.Second
+
+// Event is sent to the browsers. A "reload" event reloads the page, a "css"
+// event swaps the stylesheets which match Path.
+type Event struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"`
+}
+
+// LiveReload broadcasts events to browsers with server-sent events. It
+// serves the event stream at /events and the script at /livereload.js.
+type LiveReload struct {
+	mu      sync.Mutex
+	clients map[chan Event]struct{}
+}
+
+func NewLiveReload() *LiveReload {
+	return &LiveReload{clients: make(map[chan Event]struct{})}
+}
+
+// Reload reloads all connected pages.
+func (l *LiveReload) Reload() {
+	l.Broadcast(Event{Type: "reload"})
+}
+
+// ReloadCSS swaps the stylesheets of the connected pages without reloading
+// them.
+func (l *LiveReload) ReloadCSS(path string) {
+	l.Broadcast(Event{Type: "css", Path: path})
+}
+
+func (l *LiveReload) Broadcast(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for client := range l.clients {
+		select {
+		case client <- event:
+		default:
+			// a client which doesn't keep up misses the event, it
+			// reconnects anyway after the next reload
+		}
+	}
+}
+
+// Clients returns the number of connected browsers.
+func (l *LiveReload) Clients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.clients)
+}
+
+func (l *LiveReload) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if strings.HasSuffix(req.URL.Path, "/livereload.js") {
+		res.Header().Set("Content-Type", "application/javascript")
+		res.Write([]byte(script))
+		return
+	}
+
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		http.Error(res, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// the page is served by the application on another port
+	res.Header().Set("Access-Control-Allow-Origin", "*")
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+
+	client := make(chan Event, 8)
+	l.mu.Lock()
+	l.clients[client] = struct{}{}
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		delete(l.clients, client)
+		l.mu.Unlock()
+	}()
+
+	fmt.Fprint(res, ": connected\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(KeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(res, ": ping\n\n")
+		case event := <-client:
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// LiveReloadScript injects the live reload script into HTML pages, when the
+// application runs under the reload manager.
+func LiveReloadScript(h http.Handler) http.Handler {
+	if LiveReloadURL == "" {
+		return h
+	}
+
+	tag := []byte(fmt.Sprintf(`<script src="%s/livereload.js"></script>`, strings.TrimRight(LiveReloadURL, "/")))
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		w := &injector{ResponseWriter: res, tag: tag, status: http.StatusOK}
+		h.ServeHTTP(w, req)
+		w.finish()
+	})
+}
+
+// injector buffers HTML responses to insert the script before </body>,
+// other responses are passed through.
+type injector struct {
+	http.ResponseWriter
+	tag      []byte
+	status   int
+	decided  bool
+	html     bool
+	hijacked bool
+	buf      bytes.Buffer
+}
+
+func (w *injector) WriteHeader(status int) {
+	if w.decided {
+		return
+	}
+
+	w.status = status
+	w.decide()
+}
+
+func (w *injector) Write(b []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.decide()
+	}
+
+	if w.html {
+		return w.buf.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *injector) Flush() {
+	if w.html {
+		return
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection to the handler, e.g. for a websocket, the
+// response isn't touched afterwards.
+func (w *injector) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		w.decided = true
+		w.hijacked = true
+	}
+
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *injector) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *injector) decide() {
+	w.decided = true
+	w.html = strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") && w.Header().Get("Content-Encoding") == ""
+
+	if w.html {
+		w.Header().Del("Content-Length")
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *injector) finish() {
+	if w.hijacked {
+		return
+	}
+
+	if !w.decided {
+		w.ResponseWriter.WriteHeader(w.status)
+		return
+	}
+
+	if !w.html {
+		return
+	}
+
+	body := w.buf.Bytes()
+	i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>"))
+	if i < 0 {
+		i = len(body)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body[:i])
+	w.ResponseWriter.Write(w.tag)
+	w.ResponseWriter.Write(body[i:])
+}
+
+const script = `(function () {
+	var base = document.currentScript.src.replace(/livereload\.js.*$/, "");
+	var events = new EventSource(base + "events");
+
+	// the application may still be starting, so the page is only reloaded
+	// once it answers
+	function reload(attempts) {
+		fetch(location.href, { method: "HEAD", cache: "no-store" }).then(function () {
+			location.reload();
+		}, function () {
+			if (attempts > 0) setTimeout(function () { reload(attempts - 1); }, 200);
+		});
+	}
+
+	events.addEventListener("reload", function () {
+		reload(50);
+	});
+
+	events.addEventListener("css", function (e) {
+		var path = JSON.parse(e.data).path || "";
+		var name = path.split("/").pop();
+		var links = document.querySelectorAll('link[rel="stylesheet"]');
+		var swapped = false;
+
+		links.forEach(function (link) {
+			var url = new URL(link.href);
+			if (name && url.pathname.split("/").pop() !== name) return;
+			url.searchParams.set("livereload", Date.now());
+			link.href = url.toString();
+			swapped = true;
+		});
+
+		if (!swapped) reload(50);
+	});
+})();
+`
@@ -0,0 +1,128 @@
+package web
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveReloadEvents(t *testing.T) {
+	live := NewLiveReload()
+	server := httptest.NewServer(live)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/events")
+	assert.Nil(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	assert.Equal(t, "*", res.Header.Get("Access-Control-Allow-Origin"))
+
+	reader := bufio.NewReader(res.Body)
+	line, _ := reader.ReadString('\n')
+	assert.Equal(t, ": connected\n", line)
+	reader.ReadString('\n')
+	assert.Equal(t, 1, live.Clients())
+
+	live.ReloadCSS("assets/app.css")
+	live.Reload()
+
+	var events []string
+	for len(events) < 6 {
+		line, err := reader.ReadString('\n')
+		assert.Nil(t, err)
+		events = append(events, line)
+	}
+
+	assert.Equal(t, []string{
+		"event: css\n", "data: {\"type\":\"css\",\"path\":\"assets/app.css\"}\n", "\n",
+		"event: reload\n", "data: {\"type\":\"reload\"}\n", "\n",
+	}, events)
+
+	res.Body.Close()
+	assert.Eventually(t, func() bool { return live.Clients() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestLiveReloadServesScript(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewLiveReload().ServeHTTP(rec, httptest.NewRequest("GET", "/livereload.js", nil))
+
+	assert.Equal(t, "application/javascript", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `new EventSource(base + "events")`)
+}
+
+func TestLiveReloadScript(t *testing.T) {
+	LiveReloadURL = "http://localhost:35729"
+	defer func() { LiveReloadURL = "" }()
+
+	handler := LiveReloadScript(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/page":
+			res.Header().Set("Content-Length", "38")
+			io.WriteString(res, "<html><body><h1>Hi</h1></body></html>")
+		case "/fragment":
+			res.Header().Set("Content-Type", "text/html; charset=utf-8")
+			res.WriteHeader(http.StatusCreated)
+			io.WriteString(res, "<p>no body</p>")
+		default:
+			res.Header().Set("Content-Type", "application/json")
+			io.WriteString(res, `{"body":"</body>"}`)
+		}
+	}))
+
+	tag := `<script src="http://localhost:35729/livereload.js"></script>`
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/page", nil))
+	assert.Equal(t, "<html><body><h1>Hi</h1>"+tag+"</body></html>", rec.Body.String())
+	assert.Equal(t, "", rec.Header().Get("Content-Length"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/fragment", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "<p>no body</p>"+tag, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api", nil))
+	assert.Equal(t, `{"body":"</body>"}`, rec.Body.String())
+
+	assert.False(t, strings.Contains(rec.Body.String(), tag))
+}
+
+func TestLiveReloadScriptHijack(t *testing.T) {
+	LiveReloadURL = "http://localhost:35729"
+	defer func() { LiveReloadURL = "" }()
+
+	app := httptest.NewServer(LiveReloadScript(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		controller := http.NewResponseController(res)
+		if !assert.Nil(t, controller.SetWriteDeadline(time.Now().Add(time.Second))) {
+			return
+		}
+
+		conn, rw, err := controller.Hijack()
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	})))
+	defer app.Close()
+
+	req, _ := http.NewRequest("GET", app.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+}
@@ -0,0 +1,133 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/evolidev/console/reload/buildlog"
+)
+
+// DefaultHoldTimeout is how long a request is held while the application
+// restarts.
+const DefaultHoldTimeout = 30 * time.Second
+
+// Proxy forwards requests to the application on a stable address. While
+// the application restarts requests are held until it is ready again, and
+// while a build fails the error page is served instead. Timeout is how
+// long a request is held, DefaultHoldTimeout when zero.
+type Proxy struct {
+	Target        *url.URL
+	Timeout       time.Duration
+	LogPath       string
+	LiveReloadURL string
+
+	proxy *httputil.ReverseProxy
+	mu    sync.Mutex
+	ready chan struct{}
+}
+
+// NewProxy returns a proxy to target which holds requests until Release is
+// called.
+func NewProxy(target *url.URL, logPath string) *Proxy {
+	p := &Proxy{
+		Target:  target,
+		LogPath: logPath,
+		proxy:   httputil.NewSingleHostReverseProxy(target),
+		ready:   make(chan struct{}),
+	}
+	p.proxy.ErrorHandler = p.serveError
+
+	return p
+}
+
+// Hold holds new requests until Release is called.
+func (p *Proxy) Hold() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.ready:
+		p.ready = make(chan struct{})
+	default:
+	}
+}
+
+// Release forwards the held requests and the ones which follow.
+func (p *Proxy) Release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.ready:
+	default:
+		close(p.ready)
+	}
+}
+
+func (p *Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if !p.wait(req) {
+		http.Error(res, "The application is restarting, try again.", http.StatusServiceUnavailable)
+		return
+	}
+
+	if p.serveFailure(res) {
+		return
+	}
+
+	p.proxy.ServeHTTP(res, req)
+}
+
+// wait waits until the application is ready, it returns false when the
+// timeout expired or the client went away.
+func (p *Proxy) wait(req *http.Request) bool {
+	p.mu.Lock()
+	ready := p.ready
+	p.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	default:
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHoldTimeout
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return true
+	case <-timer.C:
+		return false
+	case <-req.Context().Done():
+		return false
+	}
+}
+
+func (p *Proxy) serveFailure(res http.ResponseWriter) bool {
+	failure, err := buildlog.Read(p.LogPath)
+	if err != nil || failure == nil {
+		return false
+	}
+
+	writeFailure(res, failure, p.LiveReloadURL)
+
+	return true
+}
+
+// serveError answers requests the application didn't, e.g. because it
+// crashed.
+func (p *Proxy) serveError(res http.ResponseWriter, req *http.Request, err error) {
+	if p.serveFailure(res) {
+		return
+	}
+
+	http.Error(res, "The application is not reachable: "+err.Error(), http.StatusBadGateway)
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evolidev/console/reload/buildlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyHoldsRequests(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("app " + req.URL.Path))
+	}))
+	defer app.Close()
+
+	target, _ := url.Parse(app.URL)
+	proxy := NewProxy(target, filepath.Join(t.TempDir(), "errors.json"))
+	server := httptest.NewServer(proxy)
+	defer server.Close()
+
+	body := make(chan string)
+	go func() {
+		res, err := http.Get(server.URL + "/users")
+		assert.Nil(t, err)
+		defer res.Body.Close()
+
+		data, _ := io.ReadAll(res.Body)
+		body <- string(data)
+	}()
+
+	select {
+	case <-body:
+		t.Fatal("the request was not held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	proxy.Release()
+	assert.Equal(t, "app /users", <-body)
+
+	proxy.Hold()
+	proxy.Timeout = 10 * time.Millisecond
+
+	res, err := http.Get(server.URL)
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
+
+func TestProxyServesFailure(t *testing.T) {
+	app := httptest.NewServer(http.NotFoundHandler())
+	target, _ := url.Parse(app.URL)
+	app.Close()
+
+	proxy := NewProxy(target, filepath.Join(t.TempDir(), "errors.json"))
+	proxy.LiveReloadURL = "http://localhost:35729"
+	proxy.Release()
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+
+	assert.Nil(t, buildlog.Write(proxy.LogPath, buildlog.Failure{
+		Phase:  buildlog.PhaseBuild,
+		Error:  "exit status 1",
+		Output: "./main.go:2:14: undefined: x\n",
+	}))
+
+	rec = httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "There was a build error!")
+	assert.Contains(t, rec.Body.String(), `<script src="http://localhost:35729/livereload.js"></script>`)
+}
@@ -3,39 +3,122 @@ package web
 import (
 	"fmt"
 	"html/template"
-	"io/ioutil"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
 
-	"github.com/markbates/refresh/refresh"
+	"github.com/evolidev/console/reload/buildlog"
 )
 
-var lpath = refresh.ErrorLogPath()
+// EditorURL is the link for a file:line of the compiler output. The
+// placeholders {file}, {line} and {column} are replaced, e.g. with
+// "idea://open?file={file}&line={line}" for GoLand. {file} is an absolute
+// path with forward slashes which always starts with a slash.
+var EditorURL = "vscode://file{file}:{line}:{column}"
+
+// LogPath is the error log which is checked, the manager passes its path to
+// the application.
+var LogPath = buildlog.Path()
+
 var tmpl *template.Template
 
 func init() {
 	tmpl, _ = template.New("template").Parse(html)
 }
 
+type page struct {
+	Phase  string
+	Error  string
+	Lines  []line
+	Script template.URL
+}
+
+type line struct {
+	Link     template.URL
+	Location string
+	Text     string
+}
+
+// ErrorChecker shows the latest build or run failure recorded by the
+// reload manager instead of the page, until the next successful build.
 func ErrorChecker(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
-		ee, err := ioutil.ReadFile(lpath)
-		if err != nil || ee == nil {
+		failure, err := buildlog.Read(LogPath)
+		if err != nil || failure == nil {
 			h.ServeHTTP(res, req)
 			return
 		}
-		res.WriteHeader(500)
-		tmplErr := tmpl.Execute(res, string(ee))
-		if tmplErr != nil {
-			// todo log to our logger
-			fmt.Println(tmplErr)
-		}
+
+		writeFailure(res, failure, LiveReloadURL)
 	})
 }
 
+// writeFailure writes the error page. With a live reload server the page
+// reloads itself once the error is fixed.
+func writeFailure(res http.ResponseWriter, failure *buildlog.Failure, liveReloadURL string) {
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.WriteHeader(http.StatusInternalServerError)
+
+	tmplErr := tmpl.Execute(res, newPage(failure, liveReloadURL))
+	if tmplErr != nil {
+		// todo log to our logger
+		fmt.Println(tmplErr)
+	}
+}
+
+func newPage(failure *buildlog.Failure, liveReloadURL string) page {
+	p := page{Phase: failure.Phase, Error: failure.Error}
+	if liveReloadURL != "" {
+		p.Script = template.URL(strings.TrimRight(liveReloadURL, "/") + "/livereload.js")
+	}
+	if strings.TrimSpace(failure.Output) == "" {
+		return p
+	}
+
+	for _, text := range strings.Split(strings.TrimRight(failure.Output, "\n"), "\n") {
+		location, rest, ok := buildlog.ParseLocation(failure.Dir, text)
+		if !ok {
+			p.Lines = append(p.Lines, line{Text: text})
+			continue
+		}
+
+		// the location is shown as the compiler printed it
+		shown := strings.TrimSpace(strings.TrimSuffix(text, rest))
+
+		p.Lines = append(p.Lines, line{
+			Link:     template.URL(editorLink(location)),
+			Location: shown,
+			Text:     rest,
+		})
+	}
+
+	return p
+}
+
+func editorLink(location buildlog.Location) string {
+	column := location.Column
+	if column == 0 {
+		column = 1
+	}
+
+	file := filepath.ToSlash(location.File)
+	if !strings.HasPrefix(file, "/") {
+		file = "/" + file
+	}
+
+	return strings.NewReplacer(
+		"{file}", (&url.URL{Path: file}).EscapedPath(),
+		"{line}", strconv.Itoa(location.Line),
+		"{column}", strconv.Itoa(column),
+	).Replace(EditorURL)
+}
+
 var html = `
 <html>
 <head>
-	<title>Refresh Build Error!</title>
+	<title>Build Error!</title>
 	<style>
 		body {
 			margin-top: 20px;
@@ -50,12 +133,22 @@ var html = `
 			padding: 5px;
 			font-size: 32px;
 		}
+		a {
+			color: #8B0000;
+		}
 	</style>
 </head>
 
+{{if eq .Phase "run"}}
+<h1>Oops!! The application crashed!</h1>
+{{else}}
 <h1>Oops!! There was a build error!</h1>
+{{end}}
+
+<pre><code>{{range .Lines}}{{if .Link}}<a href="{{.Link}}">{{.Location}}</a>{{end}}{{.Text}}
+{{else}}{{.Error}}{{end}}</code></pre>
 
-<pre><code>{{.}}</code></pre>
+{{if .Script}}<script src="{{.Script}}"></script>{{end}}
 
 </html>
 `
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/evolidev/console/reload/buildlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorChecker(t *testing.T) {
+	LogPath = filepath.Join(t.TempDir(), "errors.json")
+
+	handler := ErrorChecker(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("app"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "app", rec.Body.String())
+
+	assert.Nil(t, buildlog.Write(LogPath, buildlog.Failure{
+		Phase:  buildlog.PhaseBuild,
+		Dir:    "/app",
+		Error:  "exit status 1",
+		Output: "# example.com/app\n./main.go:2:14: undefined: <x>\n",
+	}))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "There was a build error!")
+	assert.Contains(t, rec.Body.String(), "# example.com/app\n")
+	assert.Contains(t, rec.Body.String(), `<a href="vscode://file/app/main.go:2:14">./main.go:2:14:</a> undefined: &lt;x&gt;`)
+
+	assert.Nil(t, buildlog.Clear(LogPath))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "app", rec.Body.String())
+}
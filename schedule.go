@@ -0,0 +1,123 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/evolidev/console/schedule"
+)
+
+// Schedule returns the schedule of the console. The first call registers
+// the schedule:run, schedule:work and schedule:list commands.
+//
+//	cli.Schedule().Command("emails:send --force").DailyAt("02:00").Timezone("Europe/Berlin")
+func (c *Console) Schedule() *schedule.Schedule {
+	if c.schedule != nil {
+		return c.schedule
+	}
+
+	c.schedule = schedule.New(c.Execute)
+
+	c.AddHandler("schedule:run", "Run the scheduled commands which are due", c.scheduleRun)
+	c.AddHandler("schedule:work", "Run the scheduled commands every minute in the foreground", c.scheduleWork)
+	c.AddHandler("schedule:list", "List the scheduled commands and their next run", c.scheduleList)
+
+	return c.schedule
+}
+
+func (c *Console) scheduleRun(ctx *Context) error {
+	return c.runDue(ctx, c.schedule.Due(c.schedule.Now()))
+}
+
+func (c *Console) runDue(ctx context.Context, due []*schedule.Event) error {
+	if len(due) == 0 {
+		c.Println(c.Text(249, "No scheduled commands are ready to run."))
+		return nil
+	}
+
+	var errs []error
+	for _, event := range due {
+		c.Println(c.Text(140, "Running ") + event.GetName())
+
+		err := c.schedule.Run(ctx, event)
+		if errors.Is(err, schedule.ErrOverlapping) {
+			c.Println(c.Text(214, "Skipping ") + event.GetName() + c.Text(249, ", the previous run has not finished"))
+			continue
+		}
+
+		if err != nil {
+			c.PrintErrln(c.Text(203, "Failed ") + event.GetName() + ": " + err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", event.GetName(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Console) scheduleWork(ctx *Context) error {
+	workCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c.Println(c.Text(140, "Running the schedule every minute, press Ctrl+C to stop."))
+
+	// due commands run one after another on the console, which keeps the
+	// state of the running command; when minutes passed meanwhile, the
+	// events due since the last run are run once instead of once per minute
+	last := c.schedule.Now().Truncate(time.Minute)
+
+	for {
+		timer := time.NewTimer(last.Add(time.Minute).Sub(c.schedule.Now()))
+		select {
+		case <-workCtx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		now := c.schedule.Now().Truncate(time.Minute)
+		if !now.After(last) {
+			now = last.Add(time.Minute)
+		}
+
+		// errors are printed by runDue, the loop keeps going
+		_ = c.runDue(workCtx, c.schedule.DueBetween(last, now))
+
+		last = now
+	}
+}
+
+func (c *Console) scheduleList(ctx *Context) error {
+	events := c.schedule.Events()
+	if len(events) == 0 {
+		c.Println(c.Text(249, "No commands are scheduled."))
+		return nil
+	}
+
+	now := c.schedule.Now()
+
+	table := c.newTable("COMMAND", "EXPRESSION", "TIMEZONE", "NEXT DUE")
+	for _, event := range events {
+		name := event.GetName()
+		if description := event.GetDescription(); description != "" {
+			name += " (" + description + ")"
+		}
+
+		next := "never"
+		if at := event.NextRun(now); !at.IsZero() {
+			in := strings.TrimSuffix(formatDuration(at.Sub(now).Round(time.Minute)), "0s")
+			next = at.Format("2006-01-02 15:04") + " (in " + in + ")"
+		}
+
+		table.Append([]string{name, event.GetExpression(), event.GetLocation().String(), next})
+	}
+
+	table.Render()
+
+	return nil
+}
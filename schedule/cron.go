@@ -0,0 +1,200 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a compiled cron expression with the five fields minute,
+// hour, day of month, month and day of week. Fields accept *, numbers,
+// ranges (1-5), lists (1,15) and steps (*/10, 0-30/5); months and weekdays
+// can be given by their English abbreviations. The macros @hourly, @daily,
+// @weekly, @monthly and @yearly are supported as well.
+type Expression struct {
+	source string
+
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var macros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+func ParseCron(expression string) (*Expression, error) {
+	source := strings.TrimSpace(expression)
+	if macro, ok := macros[source]; ok {
+		source = macro
+	}
+
+	parts := strings.Fields(source)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expression, len(parts))
+	}
+
+	e := &Expression{source: expression}
+	sets := []*uint64{&e.minute, &e.hour, &e.dom, &e.month, &e.dow}
+
+	for i, part := range parts {
+		set, err := fields[i].parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s", expression, err)
+		}
+		*sets[i] = set
+	}
+
+	// 7 is an alias for sunday
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1
+	}
+
+	e.domAny = parts[2] == "*"
+	e.dowAny = parts[4] == "*"
+
+	return e, nil
+}
+
+func MustParseCron(expression string) *Expression {
+	e, err := ParseCron(expression)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+func (f field) parse(part string) (uint64, error) {
+	var set uint64
+
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+			step = n
+		}
+
+		start, end := f.min, f.max
+		if rangePart != "*" {
+			low, high, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if start, err = f.value(low); err != nil {
+				return 0, err
+			}
+
+			end = start
+			if isRange {
+				if end, err = f.value(high); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = f.max
+			}
+
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, f.name)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s, expected %d-%d", s, f.name, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+// Matches reports whether the expression matches the minute of t in the
+// location of t.
+func (e *Expression) Matches(t time.Time) bool {
+	return has(e.month, int(t.Month())) && e.dayMatches(t) && has(e.hour, t.Hour()) && has(e.minute, t.Minute())
+}
+
+// Next returns the first time after t which matches the expression, in the
+// location of t. It returns the zero time if there is none within 5 years,
+// e.g. for the 31st of February.
+func (e *Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !has(e.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !e.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !has(e.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !has(e.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day of month and day of week are
+// restricted, either of them has to match.
+func (e *Expression) dayMatches(t time.Time) bool {
+	dom := has(e.dom, t.Day())
+	dow := has(e.dow, int(t.Weekday()))
+
+	if e.domAny || e.dowAny {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{"* * * * *", ""},
+		{"*/15 0-6,22 1 jan-jun mon-fri", ""},
+		{"@daily", ""},
+		{"* * * *", `invalid cron expression "* * * *": expected 5 fields, got 4`},
+		{"60 * * * *", `invalid cron expression "60 * * * *": invalid value "60" in minute, expected 0-59`},
+		{"*/0 * * * *", `invalid cron expression "*/0 * * * *": invalid step "0" in minute`},
+		{"* 5-1 * * *", `invalid cron expression "* 5-1 * * *": invalid range "5-1" in hour`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseCron(test.expression)
+		if test.err == "" {
+			assert.Nil(t, err, test.expression)
+		} else {
+			assert.EqualError(t, err, test.err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data is not available")
+	}
+
+	from := time.Date(2026, 10, 19, 14, 30, 20, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		from       time.Time
+		want       time.Time
+	}{
+		{"* * * * *", from, time.Date(2026, 10, 19, 14, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", from, time.Date(2026, 10, 19, 14, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", from, time.Date(2026, 10, 20, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", from, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", from, time.Date(2026, 10, 25, 9, 0, 0, 0, time.UTC)},
+		// day of month or day of week
+		{"0 0 1 * fri", from, time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 2 * * *", from.In(berlin), time.Date(2026, 10, 20, 2, 0, 0, 0, berlin)},
+		{"0 0 31 2 *", from, time.Time{}},
+	}
+
+	for _, test := range tests {
+		e := MustParseCron(test.expression)
+		assert.True(t, test.want.Equal(e.Next(test.from)), "%s: got %s", test.expression, e.Next(test.from))
+	}
+}
@@ -0,0 +1,296 @@
+// Package schedule runs commands periodically, replacing per-server crontab
+// files. Events are defined with fluent rules and run by calling Run every
+// minute, e.g. from the schedule:run command of a console.
+package schedule
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evolidev/console/lock"
+)
+
+var ErrOverlapping = errors.New("previous run is still running")
+
+type Schedule struct {
+	// Location is used for events without a timezone, it defaults to the
+	// local time.
+	Location *time.Location
+	// LockDir holds the lock files of events which must not overlap, it
+	// defaults to the temporary directory.
+	LockDir string
+	// Execute runs the command of an event.
+	Execute func(ctx context.Context, args []string) error
+	// Now returns the current time, it is replaced in tests.
+	Now func() time.Time
+
+	mu     sync.Mutex
+	events []*Event
+}
+
+func New(execute func(ctx context.Context, args []string) error) *Schedule {
+	return &Schedule{
+		Location: time.Local,
+		LockDir:  os.TempDir(),
+		Execute:  execute,
+		Now:      time.Now,
+	}
+}
+
+// Command schedules a command line, e.g. "emails:send --force".
+func (s *Schedule) Command(command string) *Event {
+	args := strings.Fields(command)
+
+	return s.add(&Event{args: args, name: strings.Join(args, " ")})
+}
+
+// Call schedules a function.
+func (s *Schedule) Call(name string, fn func(ctx context.Context) error) *Event {
+	return s.add(&Event{fn: fn, name: name})
+}
+
+func (s *Schedule) add(e *Event) *Event {
+	e.schedule = s
+	e.expression = MustParseCron("* * * * *")
+
+	s.mu.Lock()
+	s.events = append(s.events, e)
+	s.mu.Unlock()
+
+	return e
+}
+
+func (s *Schedule) Events() []*Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Event{}, s.events...)
+}
+
+// Due returns the events which are due at the minute of now.
+func (s *Schedule) Due(now time.Time) []*Event {
+	var due []*Event
+	for _, e := range s.Events() {
+		if e.IsDue(now) {
+			due = append(due, e)
+		}
+	}
+
+	return due
+}
+
+// DueBetween returns the events which are due at any minute after from up to
+// and including to, each of them once.
+func (s *Schedule) DueBetween(from time.Time, to time.Time) []*Event {
+	var due []*Event
+	for _, e := range s.Events() {
+		if next := e.NextRun(from); !next.IsZero() && !next.After(to) {
+			due = append(due, e)
+		}
+	}
+
+	return due
+}
+
+// Run runs an event. Events which must not overlap return ErrOverlapping
+// while a previous run holds the lock.
+func (s *Schedule) Run(ctx context.Context, e *Event) error {
+	if e.withoutOverlapping {
+		release, err := s.lock(e)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	if e.fn != nil {
+		return e.fn(ctx)
+	}
+
+	if s.Execute == nil {
+		return fmt.Errorf("schedule: no executor for %s", e.name)
+	}
+
+	return s.Execute(ctx, e.args)
+}
+
+// LockPath returns the path of the lock file of an event.
+func (s *Schedule) LockPath(e *Event) string {
+	sum := sha1.Sum([]byte(e.name + "|" + e.expression.String()))
+
+	return filepath.Join(s.LockDir, "schedule-"+hex.EncodeToString(sum[:8])+".lock")
+}
+
+func (s *Schedule) lock(e *Event) (func(), error) {
+	l := lock.New(s.LockPath(e))
+
+	err := l.TryAcquire()
+	if errors.Is(err, lock.ErrLocked) {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrOverlapping, e.name, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() { l.Release() }, nil
+}
+
+// Event is a scheduled command or function. By default it runs every
+// minute.
+type Event struct {
+	schedule *Schedule
+
+	name               string
+	description        string
+	args               []string
+	fn                 func(ctx context.Context) error
+	expression         *Expression
+	location           *time.Location
+	withoutOverlapping bool
+}
+
+func (e *Event) GetName() string {
+	return e.name
+}
+
+func (e *Event) GetArgs() []string {
+	return e.args
+}
+
+func (e *Event) GetDescription() string {
+	return e.description
+}
+
+// GetExpression returns the cron expression of the event.
+func (e *Event) GetExpression() string {
+	return e.expression.String()
+}
+
+func (e *Event) GetLocation() *time.Location {
+	if e.location != nil {
+		return e.location
+	}
+
+	if e.schedule != nil && e.schedule.Location != nil {
+		return e.schedule.Location
+	}
+
+	return time.Local
+}
+
+// IsDue reports whether the event is due at the minute of now.
+func (e *Event) IsDue(now time.Time) bool {
+	return e.expression.Matches(now.In(e.GetLocation()))
+}
+
+// NextRun returns the next time the event is due after now.
+func (e *Event) NextRun(now time.Time) time.Time {
+	return e.expression.Next(now.In(e.GetLocation()))
+}
+
+// Cron sets a cron expression. It panics when the expression is invalid.
+func (e *Event) Cron(expression string) *Event {
+	e.expression = MustParseCron(expression)
+	return e
+}
+
+func (e *Event) EveryMinute() *Event {
+	return e.Cron("* * * * *")
+}
+
+func (e *Event) EveryFiveMinutes() *Event {
+	return e.Cron("*/5 * * * *")
+}
+
+func (e *Event) EveryTenMinutes() *Event {
+	return e.Cron("*/10 * * * *")
+}
+
+func (e *Event) EveryFifteenMinutes() *Event {
+	return e.Cron("*/15 * * * *")
+}
+
+func (e *Event) EveryThirtyMinutes() *Event {
+	return e.Cron("*/30 * * * *")
+}
+
+func (e *Event) Hourly() *Event {
+	return e.Cron("0 * * * *")
+}
+
+func (e *Event) HourlyAt(minute int) *Event {
+	return e.Cron(fmt.Sprintf("%d * * * *", minute))
+}
+
+func (e *Event) Daily() *Event {
+	return e.Cron("0 0 * * *")
+}
+
+// DailyAt runs the event every day at a time like "02:00".
+func (e *Event) DailyAt(at string) *Event {
+	hour, minute := parseTime(at)
+	return e.Cron(fmt.Sprintf("%d %d * * *", minute, hour))
+}
+
+func (e *Event) Weekly() *Event {
+	return e.Cron("0 0 * * 0")
+}
+
+func (e *Event) WeeklyOn(day time.Weekday, at string) *Event {
+	hour, minute := parseTime(at)
+	return e.Cron(fmt.Sprintf("%d %d * * %d", minute, hour, day))
+}
+
+func (e *Event) Monthly() *Event {
+	return e.Cron("0 0 1 * *")
+}
+
+func (e *Event) MonthlyOn(day int, at string) *Event {
+	hour, minute := parseTime(at)
+	return e.Cron(fmt.Sprintf("%d %d %d * *", minute, hour, day))
+}
+
+// Timezone evaluates the rules of the event in a timezone like
+// "Europe/Berlin". It panics when the timezone is unknown.
+func (e *Event) Timezone(name string) *Event {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("schedule: %s", err))
+	}
+
+	return e.In(location)
+}
+
+func (e *Event) In(location *time.Location) *Event {
+	e.location = location
+	return e
+}
+
+func (e *Event) Description(description string) *Event {
+	e.description = description
+	return e
+}
+
+// WithoutOverlapping skips a run while the previous one is still running,
+// also when it runs in another process.
+func (e *Event) WithoutOverlapping() *Event {
+	e.withoutOverlapping = true
+	return e
+}
+
+func parseTime(at string) (int, int) {
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		panic(fmt.Sprintf("schedule: invalid time %q, expected HH:MM", at))
+	}
+
+	return t.Hour(), t.Minute()
+}
@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvents(t *testing.T) {
+	s := New(nil)
+	s.Location = time.UTC
+
+	daily := s.Command("emails:send  --force").DailyAt("02:00").Timezone("America/New_York")
+	hourly := s.Command("cache:prune").HourlyAt(15)
+
+	assert.Equal(t, []string{"emails:send", "--force"}, daily.GetArgs())
+	assert.Equal(t, "0 2 * * *", daily.GetExpression())
+	assert.Equal(t, "15 * * * *", hourly.GetExpression())
+	assert.Equal(t, "America/New_York", daily.GetLocation().String())
+	assert.Equal(t, time.UTC, hourly.GetLocation())
+
+	// 02:00 in New York is 06:00 UTC in October
+	at := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	assert.Equal(t, []*Event{daily}, s.Due(at))
+	assert.Equal(t, []*Event{hourly}, s.Due(at.Add(15*time.Minute)))
+
+	assert.Panics(t, func() { s.Command("x").DailyAt("2am") })
+	assert.Panics(t, func() { s.Command("x").Cron("* *") })
+}
+
+func TestDueBetween(t *testing.T) {
+	s := New(nil)
+	s.Location = time.UTC
+
+	minutely := s.Command("queue:work").EveryMinute()
+	five := s.Command("reports:build").EveryFiveMinutes()
+	s.Command("cache:prune").HourlyAt(30)
+
+	from := time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, []*Event{minutely}, s.DueBetween(from, from.Add(time.Minute)))
+	assert.Equal(t, []*Event{minutely, five}, s.DueBetween(from, from.Add(7*time.Minute)))
+	assert.Empty(t, s.DueBetween(from, from))
+}
+
+func TestRun(t *testing.T) {
+	var executed [][]string
+	s := New(func(ctx context.Context, args []string) error {
+		executed = append(executed, args)
+		return nil
+	})
+	s.LockDir = t.TempDir()
+
+	called := false
+	assert.Nil(t, s.Run(context.Background(), s.Command("migrate --force")))
+	assert.Nil(t, s.Run(context.Background(), s.Call("cleanup", func(ctx context.Context) error {
+		called = true
+		return nil
+	})))
+
+	assert.Equal(t, [][]string{{"migrate", "--force"}}, executed)
+	assert.True(t, called)
+}
+
+func TestWithoutOverlapping(t *testing.T) {
+	s := New(nil)
+	s.LockDir = t.TempDir()
+
+	var event *Event
+	event = s.Call("import", func(ctx context.Context) error {
+		_, err := os.Stat(s.LockPath(event))
+		assert.Nil(t, err)
+
+		return s.Run(ctx, event)
+	}).WithoutOverlapping()
+
+	err := s.Run(context.Background(), event)
+	assert.True(t, errors.Is(err, ErrOverlapping))
+
+	_, err = os.Stat(s.LockPath(event))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,98 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	var out, errOut strings.Builder
+
+	cli := New()
+	cli.DisableColors()
+	cli.Output = &out
+	cli.ErrOutput = &errOut
+
+	cli.AddHandler("emails:send {--force}", "", func(ctx *Context) error {
+		ctx.Println("sent")
+		return nil
+	})
+	cli.AddHandler("reports:build", "", func(ctx *Context) error {
+		return errors.New("no data")
+	})
+
+	s := cli.Schedule()
+	s.Location = time.UTC
+	s.LockDir = t.TempDir()
+	s.Now = func() time.Time {
+		return time.Date(2026, 10, 19, 2, 0, 30, 0, time.UTC)
+	}
+
+	s.Command("emails:send --force").DailyAt("02:00").Description("Daily digest")
+	s.Command("reports:build").EveryFiveMinutes().WithoutOverlapping()
+	s.Command("cache:prune").HourlyAt(30)
+
+	err := cli.Execute(context.Background(), []string{"schedule:run"})
+	assert.EqualError(t, err, "reports:build: no data")
+	assert.Equal(t, "Running emails:send --force\nsent\nRunning reports:build\n", out.String())
+	assert.Equal(t, "Failed reports:build: no data\n", errOut.String())
+
+	out.Reset()
+	assert.Nil(t, cli.Execute(context.Background(), []string{"schedule:list"}))
+	assert.Equal(t, strings.Join([]string{
+		"COMMAND                           \tEXPRESSION \tTIMEZONE\tNEXT DUE                    ",
+		"emails:send --force (Daily digest)\t0 2 * * *  \tUTC     \t2026-10-20 02:00 (in 24h0m)\t",
+		"reports:build                     \t*/5 * * * *\tUTC     \t2026-10-19 02:05 (in 5m)   \t",
+		"cache:prune                       \t30 * * * * \tUTC     \t2026-10-19 02:30 (in 30m)  \t",
+		"",
+	}, "\n"), out.String())
+}
+
+func TestScheduleWork(t *testing.T) {
+	var out strings.Builder
+
+	cli := New()
+	cli.DisableColors()
+	cli.Output = &out
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := map[string]int{}
+	count := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			runs[name]++
+			return nil
+		}
+	}
+
+	s := cli.Schedule()
+	s.Location = time.UTC
+	s.LockDir = t.TempDir()
+
+	// the first minute took seven minutes, e.g. because of a long command
+	calls := 0
+	s.Now = func() time.Time {
+		calls++
+		if calls == 1 {
+			return time.Date(2026, 10, 19, 2, 0, 30, 0, time.UTC)
+		}
+		return time.Date(2026, 10, 19, 2, 7, 10, 0, time.UTC)
+	}
+
+	s.Call("queue:work", count("queue:work")).EveryMinute()
+	s.Call("reports:build", count("reports:build")).EveryFiveMinutes()
+	s.Call("cache:prune", count("cache:prune")).HourlyAt(30)
+	s.Call("stop", func(context.Context) error {
+		cancel()
+		return nil
+	}).EveryMinute()
+
+	assert.Nil(t, cli.Execute(ctx, []string{"schedule:work"}))
+	assert.Equal(t, map[string]int{"queue:work": 1, "reports:build": 1}, runs)
+}
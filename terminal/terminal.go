@@ -0,0 +1,145 @@
+// Package terminal detects the capabilities of the terminal a writer is
+// connected to, like its size and the amount of colors it can display.
+package terminal
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/evolidev/console/color"
+	"golang.org/x/term"
+)
+
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+// LookupEnv is used to read the environment, it matches os.LookupEnv.
+type LookupEnv func(key string) (string, bool)
+
+type fileDescriptor interface {
+	Fd() uintptr
+}
+
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(fileDescriptor)
+	if !ok {
+		return false
+	}
+
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// Size returns the width and height of the terminal. When the writer is not a
+// terminal the COLUMNS and LINES variables are used, falling back to 80x24.
+func Size(w io.Writer) (int, int) {
+	return SizeFromEnv(w, os.LookupEnv)
+}
+
+func SizeFromEnv(w io.Writer, env LookupEnv) (int, int) {
+	if file, ok := w.(fileDescriptor); ok {
+		width, height, err := term.GetSize(int(file.Fd()))
+		if err == nil && width > 0 && height > 0 {
+			return width, height
+		}
+	}
+
+	return envInt(env, "COLUMNS", DefaultWidth), envInt(env, "LINES", DefaultHeight)
+}
+
+func Width(w io.Writer) int {
+	width, _ := Size(w)
+	return width
+}
+
+func Height(w io.Writer) int {
+	_, height := Size(w)
+	return height
+}
+
+// ColorProfile detects the color profile for output written to w. It honours
+// NO_COLOR, FORCE_COLOR, CLICOLOR_FORCE, CLICOLOR, TERM and COLORTERM.
+func ColorProfile(w io.Writer) color.Profile {
+	return ColorProfileFromEnv(w, os.LookupEnv)
+}
+
+func ColorProfileFromEnv(w io.Writer, env LookupEnv) color.Profile {
+	if value, ok := env("NO_COLOR"); ok && value != "" {
+		return color.NoColor
+	}
+
+	if value, ok := env("FORCE_COLOR"); ok {
+		switch strings.ToLower(value) {
+		case "0", "false":
+			return color.NoColor
+		case "2":
+			return color.ANSI256
+		case "3":
+			return color.TrueColor
+		}
+
+		return maxProfile(supportedProfile(env), color.ANSI)
+	}
+
+	if value, ok := env("CLICOLOR_FORCE"); ok && value != "" && value != "0" {
+		return maxProfile(supportedProfile(env), color.ANSI)
+	}
+
+	if !IsTerminal(w) {
+		return color.NoColor
+	}
+
+	if value, ok := env("CLICOLOR"); ok && value == "0" {
+		return color.NoColor
+	}
+
+	return supportedProfile(env)
+}
+
+// supportedProfile returns the profile the terminal advertises via TERM and
+// COLORTERM.
+func supportedProfile(env LookupEnv) color.Profile {
+	terminal, _ := env("TERM")
+	terminal = strings.ToLower(terminal)
+
+	if terminal == "dumb" {
+		return color.NoColor
+	}
+
+	colorTerm, _ := env("COLORTERM")
+	switch strings.ToLower(colorTerm) {
+	case "truecolor", "24bit":
+		return color.TrueColor
+	}
+
+	if strings.Contains(terminal, "256color") || strings.Contains(terminal, "truecolor") {
+		return color.ANSI256
+	}
+
+	return color.ANSI
+}
+
+func maxProfile(a, b color.Profile) color.Profile {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func envInt(env LookupEnv, key string, fallback int) int {
+	value, ok := env(key)
+	if !ok {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || number <= 0 {
+		return fallback
+	}
+
+	return number
+}
@@ -0,0 +1,50 @@
+package terminal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/evolidev/console/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func env(values map[string]string) LookupEnv {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func TestColorProfileFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want color.Profile
+	}{
+		{"Not a terminal", map[string]string{"TERM": "xterm-256color"}, color.NoColor},
+		{"NO_COLOR wins over FORCE_COLOR", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "3"}, color.NoColor},
+		{"FORCE_COLOR without level", map[string]string{"FORCE_COLOR": ""}, color.ANSI},
+		{"FORCE_COLOR uses the terminal level", map[string]string{"FORCE_COLOR": "1", "TERM": "xterm-256color"}, color.ANSI256},
+		{"FORCE_COLOR=0", map[string]string{"FORCE_COLOR": "0", "COLORTERM": "truecolor"}, color.NoColor},
+		{"FORCE_COLOR=3", map[string]string{"FORCE_COLOR": "3"}, color.TrueColor},
+		{"CLICOLOR_FORCE with COLORTERM", map[string]string{"CLICOLOR_FORCE": "1", "COLORTERM": "24bit"}, color.TrueColor},
+		{"CLICOLOR_FORCE=0", map[string]string{"CLICOLOR_FORCE": "0", "COLORTERM": "24bit"}, color.NoColor},
+		{"FORCE_COLOR on a dumb terminal", map[string]string{"FORCE_COLOR": "true", "TERM": "dumb"}, color.ANSI},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, ColorProfileFromEnv(&bytes.Buffer{}, env(test.env)))
+		})
+	}
+}
+
+func TestSizeFromEnv(t *testing.T) {
+	width, height := SizeFromEnv(&bytes.Buffer{}, env(map[string]string{"COLUMNS": "120", "LINES": "40"}))
+	assert.Equal(t, 120, width)
+	assert.Equal(t, 40, height)
+
+	width, height = SizeFromEnv(&bytes.Buffer{}, env(map[string]string{"COLUMNS": "wide"}))
+	assert.Equal(t, DefaultWidth, width)
+	assert.Equal(t, DefaultHeight, height)
+}